@@ -0,0 +1,683 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/atotto/clipboard"
+)
+
+const usageText = `Usage:
+  diamonds [--data <path>] [command]
+
+Commands:
+  (none)                                    Start the TUI
+  diamonds project ls [--format plain|tsv|json]
+  diamonds project add <name>
+  diamonds project rm <name>
+  diamonds color ls <project> [--format plain|tsv|json]
+  diamonds color add <project> <color> [--name <name>] [--group <group>]
+                     [--notes <notes>] [--tags <a,b>]
+  diamonds color rm <project> <color>
+  diamonds color nearest <color> [--limit <n>] [--format plain|tsv|json]
+  diamonds url ls <project> [--format plain|tsv|json]
+  diamonds url add <project> <name> <url> [--description <text>]
+                   [--env <environment>] [--tags <a,b>]
+  diamonds url rm <project> <name>
+  diamonds get <project> <url-name> [--copy]
+  diamonds get <project> --color <n> [--copy]
+                     [--as hex|rgb|hsl|tailwind|swiftui]
+  diamonds import <project> <file> [--yes]
+  diamonds export <project> css|scss|tailwind|tokens|android|ios [--out <dir>]
+  diamonds library ls [--format plain|tsv|json]
+  diamonds library add <name> <path>
+  diamonds library rm <name>
+
+The data file defaults to $XDG_DATA_HOME/diamonds/data.json or the user
+config dir, and can be overridden with --data or the DIAMONDS_DATA variable.
+`
+
+var errUsage = errors.New("invalid arguments, run 'diamonds help' for usage")
+
+// --- CLI ENTRY POINT ---
+
+// runCLI executes a non-interactive subcommand and writes its output to w.
+func runCLI(args []string, w io.Writer) error {
+	switch args[0] {
+	case "help", "-h", "--help":
+		fmt.Fprint(w, usageText)
+		return nil
+	case "project":
+		return runProjectCmd(args[1:], w)
+	case "color":
+		return runColorCmd(args[1:], w)
+	case "url":
+		return runUrlCmd(args[1:], w)
+	case "get":
+		return runGetCmd(args[1:], w)
+	case "import":
+		return runImportCmd(args[1:], w)
+	case "export":
+		return runExportCmd(args[1:], w)
+	case "library":
+		return runLibraryCmd(args[1:], w)
+	}
+	return fmt.Errorf("unknown command %q, run 'diamonds help' for usage", args[0])
+}
+
+// --- SUBCOMMANDS ---
+
+func runProjectCmd(args []string, w io.Writer) error {
+	fs := newFlagSet("project")
+	format := fs.String("format", "plain", "output format of ls: plain, tsv or json")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	projects, err := loadProjects()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "ls" && len(args) == 1:
+		return writeProjectList(w, projects, cliURLUsage(), *format)
+	case args[0] == "add" && len(args) == 2:
+		name := args[1]
+		if name == "" {
+			return errors.New("project name cannot be empty")
+		}
+		if findProject(projects, name) >= 0 {
+			return fmt.Errorf("project %q already exists", name)
+		}
+		projects = append(projects, Project{Name: name, Colors: []namedColor{}, Urls: []namedURL{}})
+		return writeProjects(projects)
+	case args[0] == "rm" && len(args) == 2:
+		i := findProject(projects, args[1])
+		if i < 0 {
+			return fmt.Errorf("project %q not found", args[1])
+		}
+		projects = append(projects[:i], projects[i+1:]...)
+		return writeProjects(projects)
+	}
+	return errUsage
+}
+
+func runColorCmd(args []string, w io.Writer) error {
+	fs := newFlagSet("color")
+	format := fs.String("format", "plain", "output format of ls: plain, tsv or json")
+	name := fs.String("name", "", "name of the added color")
+	group := fs.String("group", "", "role or group of the added color")
+	notes := fs.String("notes", "", "notes about the added color")
+	tags := fs.String("tags", "", "comma-separated tags of the added color")
+	limit := fs.Int("limit", 5, "number of colors nearest prints")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return errUsage
+	}
+	if args[0] == "nearest" && len(args) == 2 {
+		return runNearestCmd(w, args[1], *limit, *format)
+	}
+
+	projects, p, err := loadProject(args[1])
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "ls" && len(args) == 2:
+		return writeColorList(w, p.Colors, *format)
+	case args[0] == "add" && len(args) == 3:
+		value, err := normalizeColor(args[2])
+		if err != nil {
+			return fmt.Errorf("invalid color %q: %w", args[2], err)
+		}
+		if c, err := parseColor(value); err == nil {
+			// Still added, as scripts may mean to keep both
+			for _, match := range nearIdenticalColors(projects, c) {
+				existing := match.color.Value
+				if match.color.Name != "" {
+					existing += fmt.Sprintf(" (%s)", match.color.Name)
+				}
+				fmt.Fprintf(os.Stderr, "diamonds: %s is near-identical to %s in %s, ΔE %.2f\n", value, existing, match.project, match.deltaE)
+			}
+		}
+		p.Colors = append(p.Colors, namedColor{Name: *name, Value: value, Group: *group, Notes: *notes, Tags: parseTags(*tags)})
+		return writeProjects(projects)
+	case args[0] == "rm" && len(args) == 3:
+		for i, c := range p.Colors {
+			if canonicalColor(c.Value) == canonicalColor(args[2]) {
+				p.Colors = append(p.Colors[:i], p.Colors[i+1:]...)
+				return writeProjects(projects)
+			}
+		}
+		return fmt.Errorf("color %q not found in %q", args[2], p.Name)
+	}
+	return errUsage
+}
+
+// runNearestCmd prints the colors of the library closest to value.
+func runNearestCmd(w io.Writer, value string, limit int, format string) error {
+	c, err := parseColor(value)
+	if err != nil {
+		return fmt.Errorf("invalid color %q: %w", value, err)
+	}
+	projects, err := loadProjects()
+	if err != nil {
+		return err
+	}
+
+	matches := nearestColors(projects, c)
+	if limit >= 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return writeColorMatches(w, matches, format)
+}
+
+func runUrlCmd(args []string, w io.Writer) error {
+	fs := newFlagSet("url")
+	format := fs.String("format", "plain", "output format of ls: plain, tsv or json")
+	description := fs.String("description", "", "description of the added URL")
+	env := fs.String("env", "", "environment of the added URL, e.g. prod, staging or dev")
+	tags := fs.String("tags", "", "comma-separated tags of the added URL")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return errUsage
+	}
+
+	projects, p, err := loadProject(args[1])
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "ls" && len(args) == 2:
+		return writeUrlList(w, p.Urls, cliURLUsage()[p.Name], *format)
+	case args[0] == "add" && len(args) == 4:
+		newURL := namedURL{
+			Name:        args[2],
+			URL:         args[3],
+			Description: *description,
+			Environment: *env,
+			Tags:        parseTags(*tags),
+			Created:     time.Now(),
+		}
+		if !isValidURL(newURL) {
+			return errors.New("URL name and address cannot be empty")
+		}
+		p.Urls = append(p.Urls, newURL)
+		return writeProjects(projects)
+	case args[0] == "rm" && len(args) == 3:
+		for i, u := range p.Urls {
+			if u.Name == args[2] {
+				p.Urls = append(p.Urls[:i], p.Urls[i+1:]...)
+				return writeProjects(projects)
+			}
+		}
+		return fmt.Errorf("URL %q not found in %q", args[2], p.Name)
+	}
+	return errUsage
+}
+
+func runGetCmd(args []string, w io.Writer) error {
+	fs := newFlagSet("get")
+	copyValue := fs.Bool("copy", false, "copy the value to the clipboard instead of printing it")
+	colorIndex := fs.Int("color", 0, "1-based position of the color to get")
+	as := fs.String("as", string(formatHex), "format of the color: hex, rgb, hsl, tailwind or swiftui")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	asFormat, err := parseColorFormat(*as)
+	if err != nil {
+		return err
+	}
+
+	var value string
+	switch {
+	case *colorIndex != 0 && len(positional) == 1:
+		_, p, err := loadProject(positional[0])
+		if err != nil {
+			return err
+		}
+		if *colorIndex < 1 || *colorIndex > len(p.Colors) {
+			return fmt.Errorf("project %q has no color %d", p.Name, *colorIndex)
+		}
+		value, err = formatColor(p.Colors[*colorIndex-1].Value, asFormat)
+		if err != nil {
+			return err
+		}
+	case *colorIndex == 0 && len(positional) == 2:
+		_, p, err := loadProject(positional[0])
+		if err != nil {
+			return err
+		}
+		// get only reads, so it does not record when the URL was used
+		for _, u := range p.Urls {
+			if u.Name == positional[1] {
+				value = u.URL
+				break
+			}
+		}
+		if value == "" {
+			return fmt.Errorf("URL %q not found in %q", positional[1], p.Name)
+		}
+	default:
+		return errUsage
+	}
+
+	if *copyValue {
+		if err := clipboard.WriteAll(value); err != nil {
+			return fmt.Errorf("could not copy to clipboard: %w", err)
+		}
+		return nil
+	}
+	fmt.Fprintln(w, value)
+	return nil
+}
+
+// runImportCmd previews the colors a palette file would add to a project,
+// and adds them when --yes is given.
+func runImportCmd(args []string, w io.Writer) error {
+	fs := newFlagSet("import")
+	yes := fs.Bool("yes", false, "add the colors instead of only listing them")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return errUsage
+	}
+
+	projects, p, err := loadProject(args[0])
+	if err != nil {
+		return err
+	}
+	colors, err := importColors(args[1])
+	if err != nil {
+		return err
+	}
+	added, skipped := dedupeColors(p.Colors, colors)
+
+	for _, c := range added {
+		fmt.Fprintf(w, "+ %s\t%s\t%s\n", c.Value, tsvField(c.Name), tsvField(c.Group))
+	}
+	if skipped > 0 {
+		fmt.Fprintf(w, "Skipping %s already in %s\n", pluralize(skipped, "color", "colors"), p.Name)
+	}
+	if len(added) == 0 || !*yes {
+		if len(added) > 0 {
+			fmt.Fprintln(w, "Run again with --yes to add them")
+		}
+		return nil
+	}
+
+	p.Colors = append(p.Colors, added...)
+	if err := writeProjects(projects); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Imported %s\n", pluralize(len(added), "color", "colors"))
+	return nil
+}
+
+// runExportCmd prints the export to w, or writes it below --out. Formats
+// made of several files, i.e. ios, need --out.
+func runExportCmd(args []string, w io.Writer) error {
+	fs := newFlagSet("export")
+	out := fs.String("out", "", "directory to write the exported files to")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return errUsage
+	}
+
+	format, err := findExportFormat(args[1])
+	if err != nil {
+		return err
+	}
+	_, p, err := loadProject(args[0])
+	if err != nil {
+		return err
+	}
+	files, err := format.write(*p)
+	if err != nil {
+		return err
+	}
+	for _, value := range invalidColors(*p) {
+		fmt.Fprintf(os.Stderr, "diamonds: skipped invalid color %q\n", value)
+	}
+
+	if *out == "" {
+		if len(files) != 1 {
+			return fmt.Errorf("the %s export has several files, choose a directory with --out", format.name)
+		}
+		_, err := w.Write(files[0].data)
+		return err
+	}
+	written, err := writeExport(*out, files)
+	if err != nil {
+		return err
+	}
+	for _, path := range written {
+		fmt.Fprintln(w, path)
+	}
+	return nil
+}
+
+func runLibraryCmd(args []string, w io.Writer) error {
+	fs := newFlagSet("library")
+	format := fs.String("format", "plain", "output format of ls: plain, tsv or json")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	libraries, err := loadLibraries()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "ls" && len(args) == 1:
+		path, err := getDataFilePath()
+		if err != nil {
+			return err
+		}
+		all := append([]library{{Name: defaultLibraryName, Path: path}}, libraries...)
+		return writeLibraryList(w, all, *format)
+	case args[0] == "add" && len(args) == 3:
+		name := args[1]
+		if name == "" || name == defaultLibraryName {
+			return fmt.Errorf("invalid library name %q", name)
+		}
+		if findLibrary(libraries, name) >= 0 {
+			return fmt.Errorf("library %q already exists", name)
+		}
+		path, err := filepath.Abs(args[2])
+		if err != nil {
+			return fmt.Errorf("invalid library path: %w", err)
+		}
+		libraries = append(libraries, library{Name: name, Path: path})
+		return writeLibraries(libraries)
+	case args[0] == "rm" && len(args) == 2:
+		i := findLibrary(libraries, args[1])
+		if i < 0 {
+			return fmt.Errorf("library %q not found", args[1])
+		}
+		libraries = append(libraries[:i], libraries[i+1:]...)
+		return writeLibraries(libraries)
+	}
+	return errUsage
+}
+
+// --- OUTPUT ---
+
+// The JSON emitted by the ls commands is a contract for scripts, so it uses
+// its own types rather than encoding the on-disk structures directly.
+
+type projectOutput struct {
+	Name   string        `json:"name"`
+	Colors []colorOutput `json:"colors"`
+	Urls   []urlOutput   `json:"urls"`
+}
+
+type urlOutput struct {
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Environment string    `json:"environment,omitempty"`
+	Created     time.Time `json:"created,omitzero"`
+	LastUsed    time.Time `json:"lastUsed,omitzero"`
+}
+
+// newUrlOutputs converts urls, taking when they were last used from used.
+func newUrlOutputs(urls []namedURL, used map[string]time.Time) []urlOutput {
+	out := make([]urlOutput, len(urls))
+	for i, u := range urls {
+		out[i] = urlOutput{
+			Name:        u.Name,
+			URL:         u.URL,
+			Description: u.Description,
+			Tags:        u.Tags,
+			Environment: u.Environment,
+			Created:     u.Created,
+			LastUsed:    used[u.Name],
+		}
+	}
+	return out
+}
+
+type colorOutput struct {
+	Value string   `json:"value"`
+	Name  string   `json:"name,omitempty"`
+	Group string   `json:"group,omitempty"`
+	Notes string   `json:"notes,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func newColorOutputs(colors []namedColor) []colorOutput {
+	out := make([]colorOutput, len(colors))
+	for i, c := range colors {
+		out[i] = colorOutput{Value: c.Value, Name: c.Name, Group: c.Group, Notes: c.Notes, Tags: c.Tags}
+	}
+	return out
+}
+
+func writeProjectList(w io.Writer, projects []Project, usage libraryUsage, format string) error {
+	switch format {
+	case "plain":
+		for _, p := range projects {
+			fmt.Fprintln(w, p.Name)
+		}
+	case "tsv":
+		for _, p := range projects {
+			fmt.Fprintf(w, "%s\t%d\t%d\n", tsvField(p.Name), len(p.Colors), len(p.Urls))
+		}
+	case "json":
+		out := make([]projectOutput, len(projects))
+		for i, p := range projects {
+			out[i] = projectOutput{Name: p.Name, Colors: newColorOutputs(p.Colors), Urls: newUrlOutputs(p.Urls, usage[p.Name])}
+		}
+		return writeJSON(w, out)
+	default:
+		return unknownFormatError(format)
+	}
+	return nil
+}
+
+func writeColorList(w io.Writer, colors []namedColor, format string) error {
+	switch format {
+	case "plain":
+		for _, c := range colors {
+			fmt.Fprintln(w, c.Value)
+		}
+	case "tsv":
+		for i, c := range colors {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, tsvField(c.Value), tsvField(c.Name), tsvField(c.Group), tsvField(strings.Join(c.Tags, ",")))
+		}
+	case "json":
+		return writeJSON(w, newColorOutputs(colors))
+	default:
+		return unknownFormatError(format)
+	}
+	return nil
+}
+
+// matchOutput is the JSON shape of a colorMatch.
+type matchOutput struct {
+	Project string  `json:"project"`
+	Value   string  `json:"value"`
+	Name    string  `json:"name,omitempty"`
+	DeltaE  float64 `json:"deltaE"`
+}
+
+func writeColorMatches(w io.Writer, matches []colorMatch, format string) error {
+	switch format {
+	case "plain":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, m := range matches {
+			fmt.Fprintf(tw, "%.2f\t%s\t%s\t%s\n", m.deltaE, m.color.Value, m.color.Name, m.project)
+		}
+		return tw.Flush()
+	case "tsv":
+		for _, m := range matches {
+			fmt.Fprintf(w, "%.4f\t%s\t%s\t%s\n", m.deltaE, tsvField(m.color.Value), tsvField(m.color.Name), tsvField(m.project))
+		}
+	case "json":
+		out := make([]matchOutput, len(matches))
+		for i, m := range matches {
+			out[i] = matchOutput{Project: m.project, Value: m.color.Value, Name: m.color.Name, DeltaE: math.Round(m.deltaE*10000) / 10000}
+		}
+		return writeJSON(w, out)
+	default:
+		return unknownFormatError(format)
+	}
+	return nil
+}
+
+func writeUrlList(w io.Writer, urls []namedURL, used map[string]time.Time, format string) error {
+	switch format {
+	case "plain":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, u := range urls {
+			fmt.Fprintf(tw, "%s\t%s\n", u.Name, u.URL)
+		}
+		return tw.Flush()
+	case "tsv":
+		for _, u := range urls {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", tsvField(u.Name), tsvField(u.URL), tsvField(u.Environment), tsvField(strings.Join(u.Tags, ",")), tsvField(u.Description))
+		}
+	case "json":
+		return writeJSON(w, newUrlOutputs(urls, used))
+	default:
+		return unknownFormatError(format)
+	}
+	return nil
+}
+
+func writeLibraryList(w io.Writer, libraries []library, format string) error {
+	switch format {
+	case "plain":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, l := range libraries {
+			fmt.Fprintf(tw, "%s\t%s\n", l.Name, l.Path)
+		}
+		return tw.Flush()
+	case "tsv":
+		for _, l := range libraries {
+			fmt.Fprintf(w, "%s\t%s\n", tsvField(l.Name), tsvField(l.Path))
+		}
+	case "json":
+		return writeJSON(w, libraries)
+	default:
+		return unknownFormatError(format)
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// tsvField replaces the characters that would break a TSV row with spaces.
+func tsvField(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+func unknownFormatError(format string) error {
+	return fmt.Errorf("unknown format %q, expected plain, tsv or json", format)
+}
+
+// --- HELPERS ---
+
+// parseGlobalFlags applies the flags accepted before any command and returns
+// the remaining arguments.
+func parseGlobalFlags(args []string) ([]string, error) {
+	fs := newFlagSet("diamonds")
+	data := fs.String("data", os.Getenv(dataEnvVar), "path of the data file to use")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return []string{"help"}, nil
+		}
+		return nil, err
+	}
+	if err := setDataFilePath(*data); err != nil {
+		return nil, err
+	}
+	return fs.Args(), nil
+}
+
+// newFlagSet returns a flag set that reports errors to the caller instead of
+// printing them and exiting.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseArgs parses fs from args, allowing flags to appear before, after or
+// between positional arguments, and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// cliURLUsage returns when the URLs of the current library were last used.
+func cliURLUsage() libraryUsage {
+	path, err := getDataFilePath()
+	if err != nil {
+		return nil
+	}
+	return libraryURLUsage(path)
+}
+
+// loadProject loads the library and returns it together with a pointer to
+// the named project, so callers can modify it in place and write it back.
+func loadProject(name string) ([]Project, *Project, error) {
+	projects, err := loadProjects()
+	if err != nil {
+		return nil, nil, err
+	}
+	i := findProject(projects, name)
+	if i < 0 {
+		return nil, nil, fmt.Errorf("project %q not found", name)
+	}
+	return projects, &projects[i], nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestGetURLDoesNotWrite(t *testing.T) {
+	path := useTempDataFile(t)
+	writeTestFile(t, path, `{"schemaVersion":6,"projects":[{"name":"web","colors":[],"urls":[{"name":"Docs","url":"https://example.com"}]}]}`)
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runCLI([]string{"get", "web", "Docs"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "https://example.com\n" {
+		t.Errorf("got %q, want the URL", out.String())
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("get changed the data file")
+	}
+	if _, err := os.Stat(path + backupSuffix); !os.IsNotExist(err) {
+		t.Error("get wrote a backup")
+	}
+}
+
+func TestListJSONShapes(t *testing.T) {
+	path := useTempDataFile(t)
+	writeTestFile(t, path, `{"schemaVersion":6,"projects":[{"name":"web","colors":[{"value":"#FF5F87","name":"Pink","group":"Brand","tags":["cta"]},{"value":"#00AFFF"}],"urls":[{"name":"Docs","url":"https://example.com","environment":"prod"}]}]}`)
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		if err := runCLI(args, &out); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	colors := `[{"value":"#FF5F87","name":"Pink","group":"Brand","tags":["cta"]},{"value":"#00AFFF"}]`
+	urls := `[{"name":"Docs","url":"https://example.com","environment":"prod"}]`
+	assertJSON(t, []byte(run("color", "ls", "web", "--format", "json")), colors)
+	assertJSON(t, []byte(run("url", "ls", "web", "--format", "json")), urls)
+	assertJSON(t, []byte(run("project", "ls", "--format", "json")), `[{"name":"web","colors":`+colors+`,"urls":`+urls+`}]`)
+}
+
+func TestColorAddWarnsAboutNearIdenticalColors(t *testing.T) {
+	path := useTempDataFile(t)
+	writeTestFile(t, path, `{"schemaVersion":6,"projects":[{"name":"web","colors":[{"value":"#FF5F87","name":"Pink"}],"urls":[]},{"name":"docs","colors":[],"urls":[]}]}`)
+
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := os.Stderr
+	os.Stderr = stderr
+	t.Cleanup(func() { os.Stderr = previous })
+
+	for _, value := range []string{"#FF5F88", "#00AFFF"} {
+		if err := runCLI([]string{"color", "add", "docs", value}, &bytes.Buffer{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	warnings, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "diamonds: #FF5F88 is near-identical to #FF5F87 (Pink) in web, ΔE 0.26\n"
+	if string(warnings) != want {
+		t.Errorf("got warnings %q, want %q", warnings, want)
+	}
+
+	// The color is added all the same
+	projects, err := loadProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(projects[1].Colors); got != 2 {
+		t.Errorf("docs has %d colors, want 2", got)
+	}
+}
@@ -0,0 +1,888 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// --- COLOR VALUES ---
+
+// rgba is an sRGB color with 8-bit channels. Every color accepted on input is
+// converted to it and stored in its canonical hex form.
+type rgba struct {
+	r, g, b, a uint8
+}
+
+// hex returns the canonical form of c: #RRGGBB, or #RRGGBBAA when c is not
+// fully opaque.
+func (c rgba) hex() string {
+	if c.a == 255 {
+		return fmt.Sprintf("#%02X%02X%02X", c.r, c.g, c.b)
+	}
+	return fmt.Sprintf("#%02X%02X%02X%02X", c.r, c.g, c.b, c.a)
+}
+
+// normalizeColor parses s and returns its canonical hex form.
+func normalizeColor(s string) (string, error) {
+	c, err := parseColor(s)
+	if err != nil {
+		return "", err
+	}
+	return c.hex(), nil
+}
+
+// canonicalColor returns the canonical form of s, or s itself if it cannot
+// be parsed, so stored values can be compared regardless of how they were
+// written.
+func canonicalColor(s string) string {
+	if hex, err := normalizeColor(s); err == nil {
+		return hex
+	}
+	return s
+}
+
+// --- PARSING ---
+
+// parseColor accepts #RGB, #RGBA, #RRGGBB, #RRGGBBAA, rgb()/rgba(),
+// hsl()/hsla(), oklch() and CSS named colors. Functions take either the
+// legacy comma-separated or the modern space-separated syntax.
+func parseColor(s string) (rgba, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "#") {
+		return parseHexColor(s[1:])
+	}
+
+	s = strings.ToLower(s)
+	switch {
+	case s == "":
+		return rgba{}, errors.New("color is empty")
+	case strings.Contains(s, "("):
+		return parseColorFunc(s)
+	}
+
+	if hex, ok := cssNamedColors[s]; ok {
+		return parseHexColor(hex[1:])
+	}
+	return rgba{}, fmt.Errorf("unknown color name %q", s)
+}
+
+func parseHexColor(digits string) (rgba, error) {
+	for _, d := range digits {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", d) {
+			return rgba{}, fmt.Errorf("%q is not a hex digit", d)
+		}
+	}
+
+	// Expand the short forms, e.g. #F0A to #FF00AA
+	if len(digits) == 3 || len(digits) == 4 {
+		var b strings.Builder
+		for _, d := range digits {
+			b.WriteRune(d)
+			b.WriteRune(d)
+		}
+		digits = b.String()
+	}
+	if len(digits) == 6 {
+		digits += "ff"
+	}
+	if len(digits) != 8 {
+		return rgba{}, errors.New("hex colors need 3, 4, 6 or 8 digits")
+	}
+
+	v, _ := strconv.ParseUint(digits, 16, 32)
+	return rgba{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+func parseColorFunc(s string) (rgba, error) {
+	open := strings.IndexByte(s, '(')
+	if !strings.HasSuffix(s, ")") {
+		return rgba{}, errors.New("missing closing parenthesis")
+	}
+	name := strings.TrimSpace(s[:open])
+	body := strings.NewReplacer(",", " ", "/", " / ").Replace(s[open+1 : len(s)-1])
+	fields := strings.Fields(body)
+
+	// Split off the alpha channel, given either after a slash or as a
+	// fourth comma-separated argument
+	alpha := "1"
+	switch {
+	case len(fields) == 5 && fields[3] == "/":
+		alpha = fields[4]
+	case len(fields) == 4 && !strings.Contains(body, "/"):
+		alpha = fields[3]
+	case len(fields) != 3:
+		return rgba{}, fmt.Errorf("%s() needs 3 components and an optional alpha", name)
+	}
+	args := fields[:3]
+
+	a, err := parseAlpha(alpha)
+	if err != nil {
+		return rgba{}, err
+	}
+
+	var r, g, b float64
+	switch name {
+	case "rgb", "rgba":
+		r, g, b, err = parseRGBArgs(args)
+	case "hsl", "hsla":
+		r, g, b, err = parseHSLArgs(args)
+	case "oklch":
+		r, g, b, err = parseOKLCHArgs(args)
+	default:
+		return rgba{}, fmt.Errorf("unsupported color function %q", name)
+	}
+	if err != nil {
+		return rgba{}, fmt.Errorf("%s(): %w", name, err)
+	}
+
+	return rgba{to8Bit(r), to8Bit(g), to8Bit(b), to8Bit(a)}, nil
+}
+
+// parseRGBArgs parses channels given as 0-255 or as percentages.
+func parseRGBArgs(args []string) (r, g, b float64, err error) {
+	var channels [3]float64
+	for i, arg := range args {
+		v, percent, err := parseNumber(arg)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if percent {
+			v = v / 100 * 255
+		}
+		if v < 0 || v > 255 {
+			return 0, 0, 0, fmt.Errorf("channel %s is outside 0-255", arg)
+		}
+		channels[i] = v / 255
+	}
+	return channels[0], channels[1], channels[2], nil
+}
+
+// parseHSLArgs parses a hue in degrees and saturation and lightness in percent.
+func parseHSLArgs(args []string) (r, g, b float64, err error) {
+	h, err := parseHue(args[0])
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	var sl [2]float64
+	for i, arg := range args[1:] {
+		v, _, err := parseNumber(arg)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if v < 0 || v > 100 {
+			return 0, 0, 0, fmt.Errorf("%s is outside 0-100%%", arg)
+		}
+		sl[i] = v / 100
+	}
+	r, g, b = hslToRGB(h, sl[0], sl[1])
+	return r, g, b, nil
+}
+
+// parseOKLCHArgs parses a lightness (0-1 or percent), a chroma and a hue.
+func parseOKLCHArgs(args []string) (r, g, b float64, err error) {
+	l, percent, err := parseNumber(args[0])
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if percent {
+		l /= 100
+	}
+	if l < 0 || l > 1 {
+		return 0, 0, 0, fmt.Errorf("lightness %s is outside 0-1", args[0])
+	}
+
+	c, percent, err := parseNumber(args[1])
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if percent {
+		c = c / 100 * 0.4 // 100% is 0.4, as in CSS
+	}
+	if c < 0 {
+		return 0, 0, 0, fmt.Errorf("chroma %s is negative", args[1])
+	}
+
+	h, err := parseHue(args[2])
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	r, g, b = oklchToRGB(l, c, h)
+	return r, g, b, nil
+}
+
+func parseAlpha(s string) (float64, error) {
+	a, percent, err := parseNumber(s)
+	if err != nil {
+		return 0, err
+	}
+	if percent {
+		a /= 100
+	}
+	if a < 0 || a > 1 {
+		return 0, fmt.Errorf("alpha %s is outside 0-1", s)
+	}
+	return a, nil
+}
+
+// parseHue parses an angle in degrees, with or without the deg unit, and
+// wraps it into [0, 360).
+func parseHue(s string) (float64, error) {
+	v, ok := parseFloat(strings.TrimSuffix(s, "deg"))
+	if !ok {
+		return 0, fmt.Errorf("%q is not a valid hue", s)
+	}
+	return math.Mod(math.Mod(v, 360)+360, 360), nil
+}
+
+// parseNumber parses a plain number or a percentage.
+func parseNumber(s string) (v float64, percent bool, err error) {
+	percent = strings.HasSuffix(s, "%")
+	v, ok := parseFloat(strings.TrimSuffix(s, "%"))
+	if !ok {
+		return 0, false, fmt.Errorf("%q is not a number", s)
+	}
+	return v, percent, nil
+}
+
+// parseFloat parses a finite decimal number. strconv.ParseFloat also accepts
+// NaN, infinities and hex floats, none of which are valid in CSS and NaN
+// slips through every range check.
+func parseFloat(s string) (float64, bool) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) || strings.ContainsAny(s, "xX") {
+		return 0, false
+	}
+	return v, true
+}
+
+// --- CONVERSIONS ---
+
+// to8Bit converts a channel from 0-1 to 0-255, clamping values outside the
+// sRGB gamut.
+func to8Bit(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
+
+// hslToRGB converts a hue in degrees and saturation and lightness in 0-1 to
+// sRGB channels in 0-1.
+func hslToRGB(h, s, l float64) (r, g, b float64) {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return r + m, g + m, b + m
+}
+
+// oklchToRGB converts OKLCH to sRGB channels in 0-1, which may fall outside
+// that range for colors outside the sRGB gamut.
+func oklchToRGB(l, c, h float64) (r, g, b float64) {
+	hRad := h * math.Pi / 180
+	a, bb := c*math.Cos(hRad), c*math.Sin(hRad)
+
+	// OKLab to linear sRGB, see https://bottosson.github.io/posts/oklab/
+	l_ := math.Pow(l+0.3963377774*a+0.2158037573*bb, 3)
+	m_ := math.Pow(l-0.1055613458*a-0.0638541728*bb, 3)
+	s_ := math.Pow(l-0.0894841775*a-1.2914855480*bb, 3)
+
+	r = +4.0767416621*l_ - 3.3077115913*m_ + 0.2309699292*s_
+	g = -1.2684380046*l_ + 2.6097574011*m_ - 0.3413193965*s_
+	b = -0.0041960863*l_ - 0.7034186147*m_ + 1.7076147010*s_
+	return srgbEncode(r), srgbEncode(g), srgbEncode(b)
+}
+
+// labToRGB converts CIE Lab relative to the D50 white point, which swatch
+// files use, to sRGB channels in 0-1.
+func labToRGB(l, a, b float64) (red, green, blue float64) {
+	const epsilon, kappa = 216.0 / 24389, 24389.0 / 27
+	fy := (l + 16) / 116
+	fx, fz := fy+a/500, fy-b/200
+	inverse := func(t float64) float64 {
+		if t*t*t > epsilon {
+			return t * t * t
+		}
+		return (116*t - 16) / kappa
+	}
+	y := l / kappa
+	if l > kappa*epsilon {
+		y = fy * fy * fy
+	}
+	x, z := inverse(fx)*0.96422, inverse(fz)*0.82521
+
+	// XYZ (D50) to linear sRGB, Bradford-adapted
+	red = 3.1338561*x - 1.6168667*y - 0.4906146*z
+	green = -0.9787684*x + 1.9161415*y + 0.0334540*z
+	blue = 0.0719453*x - 0.2289914*y + 1.4052427*z
+	return srgbEncode(red), srgbEncode(green), srgbEncode(blue)
+}
+
+// srgbEncode applies the sRGB transfer function to a linear channel.
+func srgbEncode(v float64) float64 {
+	if v <= 0.0031308 {
+		return 12.92 * v
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// rgbToHSL converts sRGB channels in 0-1 to a hue in degrees and saturation
+// and lightness in 0-1.
+func rgbToHSL(r, g, b float64) (h, s, l float64) {
+	maxC := math.Max(r, math.Max(g, b))
+	minC := math.Min(r, math.Min(g, b))
+	l = (maxC + minC) / 2
+	d := maxC - minC
+	if d == 0 {
+		return 0, 0, l
+	}
+
+	s = d / (1 - math.Abs(2*l-1))
+	switch maxC {
+	case r:
+		h = math.Mod((g-b)/d+6, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h * 60, s, l
+}
+
+// floats returns the channels of c in 0-1.
+func (c rgba) floats() (r, g, b, a float64) {
+	return float64(c.r) / 255, float64(c.g) / 255, float64(c.b) / 255, float64(c.a) / 255
+}
+
+// over composites c onto the opaque color bg.
+func (c rgba) over(bg rgba) rgba {
+	_, _, _, a := c.floats()
+	blend := func(fg, bg uint8) uint8 {
+		return to8Bit((float64(fg)*a + float64(bg)*(1-a)) / 255)
+	}
+	return rgba{blend(c.r, bg.r), blend(c.g, bg.g), blend(c.b, bg.b), 255}
+}
+
+// --- CONTRAST ---
+
+// wcagCheck is a WCAG 2.x success criterion for the contrast of text.
+type wcagCheck struct {
+	label    string
+	minRatio float64
+}
+
+var wcagChecks = []wcagCheck{
+	{"AA", 4.5},
+	{"AA Large", 3},
+	{"AAA", 7},
+	{"AAA Large", 4.5},
+}
+
+var white = rgba{255, 255, 255, 255}
+
+// contrastRatio returns the WCAG 2.x contrast ratio of text in fg on bg,
+// from 1 to 21. Translucent colors are composited first, bg over white and
+// fg over bg.
+func contrastRatio(fg, bg rgba) float64 {
+	bg = bg.over(white)
+	fg = fg.over(bg)
+	l1, l2 := fg.luminance(), bg.luminance()
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// formatRatio writes a contrast ratio as e.g. "4.52:1". It truncates rather
+// than rounds, so a failing ratio never reads as passing.
+func formatRatio(ratio float64) string {
+	return fmt.Sprintf("%.2f:1", math.Floor(ratio*100)/100)
+}
+
+// luminance returns the relative luminance of c, ignoring its alpha.
+func (c rgba) luminance() float64 {
+	r, g, b, _ := c.floats()
+	return 0.2126*srgbDecode(r) + 0.7152*srgbDecode(g) + 0.0722*srgbDecode(b)
+}
+
+// srgbDecode removes the sRGB transfer function from a channel.
+func srgbDecode(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// --- COLOR DIFFERENCE ---
+
+// similarDeltaE is the CIEDE2000 difference below which two colors count as
+// near-identical; differences under 1 are not perceptible at all.
+const similarDeltaE = 2.0
+
+// maxSimilarColors is how many near-identical colors are pointed out.
+const maxSimilarColors = 3
+
+// colorMatch is a stored color and its difference to a searched color.
+type colorMatch struct {
+	project string
+	index   int
+	color   namedColor
+	deltaE  float64
+}
+
+// nearestColors returns the colors of all projects ordered by their CIEDE2000
+// difference to c, closest first. Colors that do not parse are skipped.
+func nearestColors(projects []Project, c rgba) []colorMatch {
+	var matches []colorMatch
+	for _, p := range projects {
+		for i, color := range p.Colors {
+			other, err := parseColor(color.Value)
+			if err != nil {
+				continue
+			}
+			matches = append(matches, colorMatch{project: p.Name, index: i, color: color, deltaE: deltaE2000(c, other)})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].deltaE < matches[j].deltaE })
+	return matches
+}
+
+// nearIdenticalColors returns up to maxSimilarColors colors of projects whose
+// difference to c is below similarDeltaE, closest first.
+func nearIdenticalColors(projects []Project, c rgba) []colorMatch {
+	var similar []colorMatch
+	for _, match := range nearestColors(projects, c) {
+		if match.deltaE >= similarDeltaE || len(similar) == maxSimilarColors {
+			break
+		}
+		similar = append(similar, match)
+	}
+	return similar
+}
+
+// deltaE2000 returns the CIEDE2000 color difference of x and y, ignoring
+// alpha.
+func deltaE2000(x, y rgba) float64 {
+	l1, a1, b1 := x.lab()
+	l2, a2, b2 := y.lab()
+	return ciede2000(l1, a1, b1, l2, a2, b2)
+}
+
+// ciede2000 returns the CIEDE2000 difference of two Lab colors, following
+// Sharma, Wu and Dalal (2005).
+func ciede2000(l1, a1, b1, l2, a2, b2 float64) float64 {
+	c1, c2 := math.Hypot(a1, b1), math.Hypot(a2, b2)
+	cMean7 := math.Pow((c1+c2)/2, 7)
+	g := 0.5 * (1 - math.Sqrt(cMean7/(cMean7+math.Pow(25, 7))))
+	a1, a2 = a1*(1+g), a2*(1+g)
+	c1, c2 = math.Hypot(a1, b1), math.Hypot(a2, b2)
+
+	hue := func(a, b float64) float64 {
+		if a == 0 && b == 0 {
+			return 0
+		}
+		return math.Mod(math.Atan2(b, a)*180/math.Pi+360, 360)
+	}
+	h1, h2 := hue(a1, b1), hue(a2, b2)
+
+	dL, dC := l2-l1, c2-c1
+	dh := h2 - h1
+	switch {
+	case c1*c2 == 0:
+		dh = 0
+	case dh > 180:
+		dh -= 360
+	case dh < -180:
+		dh += 360
+	}
+	dH := 2 * math.Sqrt(c1*c2) * math.Sin(dh*math.Pi/360)
+
+	lMean, cMean := (l1+l2)/2, (c1+c2)/2
+	hMean := h1 + h2
+	switch {
+	case c1*c2 == 0:
+	case math.Abs(h1-h2) <= 180:
+		hMean /= 2
+	case h1+h2 < 360:
+		hMean = (hMean + 360) / 2
+	default:
+		hMean = (hMean - 360) / 2
+	}
+
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	t := 1 - 0.17*math.Cos(rad(hMean-30)) + 0.24*math.Cos(rad(2*hMean)) +
+		0.32*math.Cos(rad(3*hMean+6)) - 0.20*math.Cos(rad(4*hMean-63))
+	sL := 1 + 0.015*(lMean-50)*(lMean-50)/math.Sqrt(20+(lMean-50)*(lMean-50))
+	sC := 1 + 0.045*cMean
+	sH := 1 + 0.015*cMean*t
+	cMean7 = math.Pow(cMean, 7)
+	rT := -2 * math.Sqrt(cMean7/(cMean7+math.Pow(25, 7))) *
+		math.Sin(rad(60*math.Exp(-math.Pow((hMean-275)/25, 2))))
+
+	return math.Sqrt(math.Pow(dL/sL, 2) + math.Pow(dC/sC, 2) + math.Pow(dH/sH, 2) + rT*(dC/sC)*(dH/sH))
+}
+
+// lab converts c, ignoring its alpha, to CIE Lab relative to D65.
+func (c rgba) lab() (l, a, b float64) {
+	r, g, bl, _ := c.floats()
+	r, g, bl = srgbDecode(r), srgbDecode(g), srgbDecode(bl)
+
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*bl) / 0.95047
+	y := 0.2126729*r + 0.7151522*g + 0.0721750*bl
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*bl) / 1.08883
+
+	const epsilon, kappa = 216.0 / 24389, 24389.0 / 27
+	f := func(t float64) float64 {
+		if t > epsilon {
+			return math.Cbrt(t)
+		}
+		return (kappa*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+
+// --- VISION SIMULATION ---
+
+// visionDeficiency is a color vision deficiency colors can be simulated for.
+type visionDeficiency int
+
+const (
+	normalVision visionDeficiency = iota
+	protanopia
+	deuteranopia
+	tritanopia
+	achromatopsia
+)
+
+func (v visionDeficiency) String() string {
+	switch v {
+	case protanopia:
+		return "protanopia"
+	case deuteranopia:
+		return "deuteranopia"
+	case tritanopia:
+		return "tritanopia"
+	case achromatopsia:
+		return "achromatopsia"
+	}
+	return "normal vision"
+}
+
+// visionMatrices are the full-severity matrices of Machado, Oliveira and
+// Fernandes (2009), applied to linear sRGB.
+var visionMatrices = map[visionDeficiency][3][3]float64{
+	protanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	deuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+	tritanopia: {
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.145602},
+		{0.004733, 0.691367, 0.303900},
+	},
+}
+
+// simulate returns c as it looks with the deficiency v. Achromatopsia keeps
+// only the luminance.
+func (v visionDeficiency) simulate(c rgba) rgba {
+	if v == normalVision {
+		return c
+	}
+	r, g, b, _ := c.floats()
+	lin := [3]float64{srgbDecode(r), srgbDecode(g), srgbDecode(b)}
+
+	var out [3]float64
+	if v == achromatopsia {
+		y := c.luminance()
+		out = [3]float64{y, y, y}
+	} else {
+		m := visionMatrices[v]
+		for i := range out {
+			out[i] = m[i][0]*lin[0] + m[i][1]*lin[1] + m[i][2]*lin[2]
+		}
+	}
+	return rgba{to8Bit(srgbEncode(out[0])), to8Bit(srgbEncode(out[1])), to8Bit(srgbEncode(out[2])), c.a}
+}
+
+// indistinguishableDistance is the OKLab distance below which two colors are
+// hard to tell apart.
+const indistinguishableDistance = 0.05
+
+// colorDistance returns the Euclidean distance of x and y in OKLab.
+func colorDistance(x, y rgba) float64 {
+	l1, a1, b1 := x.oklab()
+	l2, a2, b2 := y.oklab()
+	return math.Sqrt((l1-l2)*(l1-l2) + (a1-a2)*(a1-a2) + (b1-b2)*(b1-b2))
+}
+
+// oklab converts c, ignoring its alpha, to OKLab.
+func (c rgba) oklab() (l, a, b float64) {
+	r, g, bl, _ := c.floats()
+	r, g, bl = srgbDecode(r), srgbDecode(g), srgbDecode(bl)
+
+	// Linear sRGB to OKLab, see https://bottosson.github.io/posts/oklab/
+	l_ := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*bl)
+	m_ := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*bl)
+	s_ := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*bl)
+
+	l = 0.2104542553*l_ + 0.7936177850*m_ - 0.0040720468*s_
+	a = 1.9779984951*l_ - 2.4285922050*m_ + 0.4505937099*s_
+	b = 0.0259040371*l_ + 0.7827717662*m_ - 0.8086757660*s_
+	return l, a, b
+}
+
+// --- FORMATTING ---
+
+// colorFormat is a notation colors can be copied in.
+type colorFormat string
+
+const (
+	formatHex      colorFormat = "hex"
+	formatRGB      colorFormat = "rgb"
+	formatHSL      colorFormat = "hsl"
+	formatTailwind colorFormat = "tailwind"
+	formatSwiftUI  colorFormat = "swiftui"
+)
+
+// colorFormats lists every colorFormat in the order they are offered.
+var colorFormats = []colorFormat{formatHex, formatRGB, formatHSL, formatTailwind, formatSwiftUI}
+
+func (f colorFormat) label() string {
+	switch f {
+	case formatRGB:
+		return "rgb()"
+	case formatHSL:
+		return "hsl()"
+	case formatTailwind:
+		return "Tailwind"
+	case formatSwiftUI:
+		return "SwiftUI"
+	}
+	return "HEX"
+}
+
+func parseColorFormat(s string) (colorFormat, error) {
+	for _, f := range colorFormats {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown color format %q, expected hex, rgb, hsl, tailwind or swiftui", s)
+}
+
+// formatColor writes the color value in format f.
+func formatColor(value string, f colorFormat) (string, error) {
+	c, err := parseColor(value)
+	if err != nil {
+		return "", err
+	}
+	r, g, b, a := c.floats()
+
+	switch f {
+	case formatRGB:
+		if c.a == 255 {
+			return fmt.Sprintf("rgb(%d, %d, %d)", c.r, c.g, c.b), nil
+		}
+		return fmt.Sprintf("rgba(%d, %d, %d, %s)", c.r, c.g, c.b, formatFloat(a, 2)), nil
+	case formatHSL:
+		h, s, l := rgbToHSL(r, g, b)
+		hsl := fmt.Sprintf("%s, %s%%, %s%%", formatFloat(h, 1), formatFloat(s*100, 1), formatFloat(l*100, 1))
+		if c.a == 255 {
+			return "hsl(" + hsl + ")", nil
+		}
+		return "hsla(" + hsl + ", " + formatFloat(a, 2) + ")", nil
+	case formatTailwind:
+		return "[" + c.hex() + "]", nil
+	case formatSwiftUI:
+		swift := fmt.Sprintf("Color(red: %.3f, green: %.3f, blue: %.3f", r, g, b)
+		if c.a != 255 {
+			swift += fmt.Sprintf(", opacity: %.3f", a)
+		}
+		return swift + ")", nil
+	}
+	return c.hex(), nil
+}
+
+// formatFloat rounds v to the given number of decimals and drops trailing zeros.
+func formatFloat(v float64, decimals int) string {
+	p := math.Pow(10, float64(decimals))
+	return strconv.FormatFloat(math.Round(v*p)/p, 'f', -1, 64)
+}
+
+// --- NAMED COLORS ---
+
+// cssNamedColors maps the CSS Color Module Level 4 keywords to hex.
+var cssNamedColors = map[string]string{
+	"aliceblue":            "#F0F8FF",
+	"antiquewhite":         "#FAEBD7",
+	"aqua":                 "#00FFFF",
+	"aquamarine":           "#7FFFD4",
+	"azure":                "#F0FFFF",
+	"beige":                "#F5F5DC",
+	"bisque":               "#FFE4C4",
+	"black":                "#000000",
+	"blanchedalmond":       "#FFEBCD",
+	"blue":                 "#0000FF",
+	"blueviolet":           "#8A2BE2",
+	"brown":                "#A52A2A",
+	"burlywood":            "#DEB887",
+	"cadetblue":            "#5F9EA0",
+	"chartreuse":           "#7FFF00",
+	"chocolate":            "#D2691E",
+	"coral":                "#FF7F50",
+	"cornflowerblue":       "#6495ED",
+	"cornsilk":             "#FFF8DC",
+	"crimson":              "#DC143C",
+	"cyan":                 "#00FFFF",
+	"darkblue":             "#00008B",
+	"darkcyan":             "#008B8B",
+	"darkgoldenrod":        "#B8860B",
+	"darkgray":             "#A9A9A9",
+	"darkgreen":            "#006400",
+	"darkgrey":             "#A9A9A9",
+	"darkkhaki":            "#BDB76B",
+	"darkmagenta":          "#8B008B",
+	"darkolivegreen":       "#556B2F",
+	"darkorange":           "#FF8C00",
+	"darkorchid":           "#9932CC",
+	"darkred":              "#8B0000",
+	"darksalmon":           "#E9967A",
+	"darkseagreen":         "#8FBC8F",
+	"darkslateblue":        "#483D8B",
+	"darkslategray":        "#2F4F4F",
+	"darkslategrey":        "#2F4F4F",
+	"darkturquoise":        "#00CED1",
+	"darkviolet":           "#9400D3",
+	"deeppink":             "#FF1493",
+	"deepskyblue":          "#00BFFF",
+	"dimgray":              "#696969",
+	"dimgrey":              "#696969",
+	"dodgerblue":           "#1E90FF",
+	"firebrick":            "#B22222",
+	"floralwhite":          "#FFFAF0",
+	"forestgreen":          "#228B22",
+	"fuchsia":              "#FF00FF",
+	"gainsboro":            "#DCDCDC",
+	"ghostwhite":           "#F8F8FF",
+	"gold":                 "#FFD700",
+	"goldenrod":            "#DAA520",
+	"gray":                 "#808080",
+	"green":                "#008000",
+	"greenyellow":          "#ADFF2F",
+	"grey":                 "#808080",
+	"honeydew":             "#F0FFF0",
+	"hotpink":              "#FF69B4",
+	"indianred":            "#CD5C5C",
+	"indigo":               "#4B0082",
+	"ivory":                "#FFFFF0",
+	"khaki":                "#F0E68C",
+	"lavender":             "#E6E6FA",
+	"lavenderblush":        "#FFF0F5",
+	"lawngreen":            "#7CFC00",
+	"lemonchiffon":         "#FFFACD",
+	"lightblue":            "#ADD8E6",
+	"lightcoral":           "#F08080",
+	"lightcyan":            "#E0FFFF",
+	"lightgoldenrodyellow": "#FAFAD2",
+	"lightgray":            "#D3D3D3",
+	"lightgreen":           "#90EE90",
+	"lightgrey":            "#D3D3D3",
+	"lightpink":            "#FFB6C1",
+	"lightsalmon":          "#FFA07A",
+	"lightseagreen":        "#20B2AA",
+	"lightskyblue":         "#87CEFA",
+	"lightslategray":       "#778899",
+	"lightslategrey":       "#778899",
+	"lightsteelblue":       "#B0C4DE",
+	"lightyellow":          "#FFFFE0",
+	"lime":                 "#00FF00",
+	"limegreen":            "#32CD32",
+	"linen":                "#FAF0E6",
+	"magenta":              "#FF00FF",
+	"maroon":               "#800000",
+	"mediumaquamarine":     "#66CDAA",
+	"mediumblue":           "#0000CD",
+	"mediumorchid":         "#BA55D3",
+	"mediumpurple":         "#9370DB",
+	"mediumseagreen":       "#3CB371",
+	"mediumslateblue":      "#7B68EE",
+	"mediumspringgreen":    "#00FA9A",
+	"mediumturquoise":      "#48D1CC",
+	"mediumvioletred":      "#C71585",
+	"midnightblue":         "#191970",
+	"mintcream":            "#F5FFFA",
+	"mistyrose":            "#FFE4E1",
+	"moccasin":             "#FFE4B5",
+	"navajowhite":          "#FFDEAD",
+	"navy":                 "#000080",
+	"oldlace":              "#FDF5E6",
+	"olive":                "#808000",
+	"olivedrab":            "#6B8E23",
+	"orange":               "#FFA500",
+	"orangered":            "#FF4500",
+	"orchid":               "#DA70D6",
+	"palegoldenrod":        "#EEE8AA",
+	"palegreen":            "#98FB98",
+	"paleturquoise":        "#AFEEEE",
+	"palevioletred":        "#DB7093",
+	"papayawhip":           "#FFEFD5",
+	"peachpuff":            "#FFDAB9",
+	"peru":                 "#CD853F",
+	"pink":                 "#FFC0CB",
+	"plum":                 "#DDA0DD",
+	"powderblue":           "#B0E0E6",
+	"purple":               "#800080",
+	"rebeccapurple":        "#663399",
+	"red":                  "#FF0000",
+	"rosybrown":            "#BC8F8F",
+	"royalblue":            "#4169E1",
+	"saddlebrown":          "#8B4513",
+	"salmon":               "#FA8072",
+	"sandybrown":           "#F4A460",
+	"seagreen":             "#2E8B57",
+	"seashell":             "#FFF5EE",
+	"sienna":               "#A0522D",
+	"silver":               "#C0C0C0",
+	"skyblue":              "#87CEEB",
+	"slateblue":            "#6A5ACD",
+	"slategray":            "#708090",
+	"slategrey":            "#708090",
+	"snow":                 "#FFFAFA",
+	"springgreen":          "#00FF7F",
+	"steelblue":            "#4682B4",
+	"tan":                  "#D2B48C",
+	"teal":                 "#008080",
+	"thistle":              "#D8BFD8",
+	"tomato":               "#FF6347",
+	"transparent":          "#00000000",
+	"turquoise":            "#40E0D0",
+	"violet":               "#EE82EE",
+	"wheat":                "#F5DEB3",
+	"white":                "#FFFFFF",
+	"whitesmoke":           "#F5F5F5",
+	"yellow":               "#FFFF00",
+	"yellowgreen":          "#9ACD32",
+}
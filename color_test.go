@@ -0,0 +1,113 @@
+package main
+
+import "testing"
+
+func TestNormalizeColor(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		// Hex
+		{"#f0a", "#FF00AA"},
+		{"#F0A8", "#FF00AA88"},
+		{"#ff5f87", "#FF5F87"},
+		{"#FF5F8780", "#FF5F8780"},
+		{"#FF5F87FF", "#FF5F87"},
+		{"  #ff5f87 ", "#FF5F87"},
+
+		// rgb() and rgba()
+		{"rgb(255, 95, 135)", "#FF5F87"},
+		{"rgb(255 95 135)", "#FF5F87"},
+		{"RGB(255, 0, 0)", "#FF0000"},
+		{"rgb(100% 0% 50%)", "#FF0080"},
+		{"rgb(127.5 0 0)", "#800000"},
+		{"rgba(255, 95, 135, 0.5)", "#FF5F8780"},
+		{"rgb(255 95 135 / 50%)", "#FF5F8780"},
+		{"rgba(0, 0, 0, 0)", "#00000000"},
+
+		// hsl() and hsla()
+		{"hsl(0, 100%, 50%)", "#FF0000"},
+		{"hsl(120deg 100% 25%)", "#008000"},
+		{"hsl(-120 100% 50%)", "#0000FF"},
+		{"hsl(480 100% 50%)", "#00FF00"},
+		{"hsla(240, 100%, 50%, 0.25)", "#0000FF40"},
+		{"hsl(0 0% 100% / 10%)", "#FFFFFF1A"},
+
+		// oklch()
+		{"oklch(1 0 0)", "#FFFFFF"},
+		{"oklch(0% 0 0)", "#000000"},
+		{"oklch(62.8% 0.2577 29.23)", "#FF0000"},
+		{"oklch(0.628 0.2577 29.23deg / 0.5)", "#FF000080"},
+
+		// Named colors
+		{"rebeccapurple", "#663399"},
+		{"Red", "#FF0000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := normalizeColor(tt.in)
+			if err != nil {
+				t.Fatalf("normalizeColor(%q) failed: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeColor(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeColorRejects(t *testing.T) {
+	tests := []string{
+		// Hex
+		"",
+		"#",
+		"#GGGGGG",
+		"#12345Z",
+		"#12",
+		"#12345",
+		"#1234567",
+		"#123456789",
+
+		// Out of range
+		"rgb(256, 0, 0)",
+		"rgb(-1 0 0)",
+		"rgb(101% 0% 0%)",
+		"rgb(0 0 0 / 2)",
+		"rgb(0 0 0 / -10%)",
+		"hsl(0 101% 50%)",
+		"hsl(0 50% -1%)",
+		"oklch(1.5 0 0)",
+		"oklch(0.5 -0.1 0)",
+
+		// Not finite decimal numbers
+		"rgb(nan, 0, 0)",
+		"rgb(NaN 0 0)",
+		"rgb(inf 0 0)",
+		"rgb(-Inf 0 0)",
+		"rgb(1e400 0 0)",
+		"rgb(0x10 0 0)",
+		"rgb(0 0 0 / nan)",
+		"hsl(inf 50% 50%)",
+		"hsl(nan 50% 50%)",
+		"hsl(infinity 50% 50%)",
+		"hsl(0 nan% 50%)",
+		"oklch(0.5 0.1 infinity)",
+		"oklch(nan 0.1 0)",
+
+		// Malformed
+		"rgb(0, 0)",
+		"rgb(0 0 0 0 0)",
+		"rgb(0 0 0",
+		"rgb(a b c)",
+		"cmyk(0 0 0 0)",
+		"notacolor",
+	}
+
+	for _, in := range tests {
+		t.Run(in, func(t *testing.T) {
+			if got, err := normalizeColor(in); err == nil {
+				t.Errorf("normalizeColor(%q) = %s, want an error", in, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// --- DATA STRUCTURES ---
+
+// exportFormat is a design-token format a project's colors can be written in.
+type exportFormat struct {
+	name  string
+	label string
+	write func(Project) ([]exportFile, error)
+}
+
+// exportFile is one file produced by an export, with a path relative to the
+// export directory.
+type exportFile struct {
+	path string
+	data []byte
+}
+
+var exportFormats = []exportFormat{
+	{"css", "CSS custom properties", exportCSS},
+	{"scss", "SCSS variables", exportSCSS},
+	{"tailwind", "Tailwind theme.colors", exportTailwind},
+	{"tokens", "W3C Design Tokens", exportTokens},
+	{"android", "Android colors.xml", exportAndroid},
+	{"ios", "iOS asset catalog", exportIOS},
+}
+
+func findExportFormat(name string) (exportFormat, error) {
+	for _, f := range exportFormats {
+		if f.name == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+	return exportFormat{}, fmt.Errorf("unknown export format %q, expected css, scss, tailwind, tokens, android or ios", name)
+}
+
+// tokenColor is a color together with the identifiers exporters use for it.
+type tokenColor struct {
+	color namedColor
+	rgba  rgba
+	group string // Slug of the group, may be empty
+	name  string // Slug of the name, unique within the group
+}
+
+// flatName joins group and name with sep, e.g. "primary-brand-pink". As
+// most formats need identifiers, names starting with a digit get a "color"
+// prefix.
+func (t tokenColor) flatName(sep string) string {
+	name := t.name
+	if t.group != "" {
+		name = t.group + sep + t.name
+	}
+	if unicode.IsDigit(rune(name[0])) {
+		name = "color" + sep + name
+	}
+	return name
+}
+
+// --- NAMING ---
+
+// tokenColors parses the colors of p and gives each a slug. Colors without a
+// name are called after their position, e.g. "color-3". Values that cannot be
+// parsed are left out; see invalidColors.
+//
+// Clashing names are numbered until every token is unique in each format:
+// within its group, as a flat name, and, for ungrouped colors, against the
+// group names nested formats use as keys.
+func tokenColors(p Project) []tokenColor {
+	groups := map[string]bool{}
+	for _, c := range p.Colors {
+		groups[slugify(c.Group)] = true
+	}
+
+	tokens := make([]tokenColor, 0, len(p.Colors))
+	used := map[string]bool{}
+	for i, c := range p.Colors {
+		value, err := parseColor(c.Value)
+		if err != nil {
+			continue
+		}
+
+		name := slugify(c.Name)
+		if name == "" {
+			name = fmt.Sprintf("color-%d", i+1)
+		}
+		t := tokenColor{color: c, rgba: value, group: slugify(c.Group), name: name}
+		taken := func() bool {
+			return used[t.group+"\x00"+t.name] || used[t.flatName("-")] || (t.group == "" && groups[t.name])
+		}
+		for n := 2; taken(); n++ {
+			t.name = fmt.Sprintf("%s-%d", name, n)
+		}
+		used[t.group+"\x00"+t.name] = true
+		used[t.flatName("-")] = true
+
+		tokens = append(tokens, t)
+	}
+	return tokens
+}
+
+// invalidColors returns the values of p that cannot be parsed and so are left
+// out of exports, e.g. "#GGGGGG" stored before colors were validated.
+func invalidColors(p Project) []string {
+	var invalid []string
+	for _, c := range p.Colors {
+		if _, err := parseColor(c.Value); err != nil {
+			invalid = append(invalid, c.Value)
+		}
+	}
+	return invalid
+}
+
+// slugify lowercases s and replaces every run of other characters than ASCII
+// letters and digits with a single dash. Identifiers such as Android resource
+// names only allow ASCII, so accented Latin letters are transliterated, e.g.
+// "Grün" becomes "grun", and other letters are dropped.
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		letters := string(r)
+		if r > unicode.MaxASCII {
+			letters = asciiLetters[r]
+		}
+		if letters != "" && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteString(letters)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// asciiLetters transliterates the lowercase Latin letters with diacritics
+// that are common in color names.
+var asciiLetters = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a", 'ă': "a",
+	'æ': "ae", 'ç': "c", 'č': "c", 'ć': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e", 'ğ': "g",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i", 'ł': "l", 'ľ': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o",
+	'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe", 'ř': "r", 'ß': "ss", 'š': "s", 'ś': "s",
+	'ş': "s", 'ť': "t", 'þ': "th", 'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u",
+	'ů': "u", 'ű': "u", 'ý': "y", 'ÿ': "y", 'ž': "z", 'ź': "z", 'ż': "z",
+}
+
+// exportBaseName returns the file name exports of p are called after.
+func exportBaseName(p Project) string {
+	if name := slugify(p.Name); name != "" {
+		return name
+	}
+	return "colors"
+}
+
+// groupTokens returns the group slugs of tokens in order of appearance, with
+// the ungrouped colors first.
+func groupTokens(tokens []tokenColor) (groups []string, byGroup map[string][]tokenColor) {
+	byGroup = map[string][]tokenColor{}
+	for _, t := range tokens {
+		if _, ok := byGroup[t.group]; !ok && t.group != "" {
+			groups = append(groups, t.group)
+		}
+		byGroup[t.group] = append(byGroup[t.group], t)
+	}
+	return groups, byGroup
+}
+
+// --- SERIALIZERS ---
+
+func exportCSS(p Project) ([]exportFile, error) {
+	tokens := tokenColors(p)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "/* %s */\n:root {\n", p.Name)
+	for _, t := range tokens {
+		fmt.Fprintf(&b, "  --%s: %s;\n", t.flatName("-"), t.color.Value)
+	}
+	b.WriteString("}\n")
+	return []exportFile{{exportBaseName(p) + ".css", b.Bytes()}}, nil
+}
+
+func exportSCSS(p Project) ([]exportFile, error) {
+	tokens := tokenColors(p)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// %s\n", p.Name)
+	for _, t := range tokens {
+		fmt.Fprintf(&b, "$%s: %s;\n", t.flatName("-"), t.color.Value)
+	}
+	return []exportFile{{"_" + exportBaseName(p) + ".scss", b.Bytes()}}, nil
+}
+
+// exportTailwind writes a config module whose colors can be spread into
+// theme.colors or theme.extend.colors. Groups become nested objects, so a
+// color is used as e.g. bg-primary-brand-pink.
+func exportTailwind(p Project) ([]exportFile, error) {
+	tokens := tokenColors(p)
+	groups, byGroup := groupTokens(tokens)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// %s\nmodule.exports = {\n  theme: {\n    colors: {\n", p.Name)
+	for _, t := range byGroup[""] {
+		fmt.Fprintf(&b, "      %s: %s,\n", strconv.Quote(t.name), strconv.Quote(t.color.Value))
+	}
+	for _, g := range groups {
+		fmt.Fprintf(&b, "      %s: {\n", strconv.Quote(g))
+		for _, t := range byGroup[g] {
+			fmt.Fprintf(&b, "        %s: %s,\n", strconv.Quote(t.name), strconv.Quote(t.color.Value))
+		}
+		b.WriteString("      },\n")
+	}
+	b.WriteString("    },\n  },\n};\n")
+	return []exportFile{{"tailwind.colors.js", b.Bytes()}}, nil
+}
+
+// designToken is a color token of the W3C Design Tokens format.
+type designToken struct {
+	Type        string `json:"$type"`
+	Value       string `json:"$value"`
+	Description string `json:"$description,omitempty"`
+}
+
+func exportTokens(p Project) ([]exportFile, error) {
+	tokens := tokenColors(p)
+
+	root := map[string]any{}
+	for _, t := range tokens {
+		token := designToken{Type: "color", Value: t.color.Value, Description: t.color.Notes}
+		if t.group == "" {
+			root[t.name] = token
+			continue
+		}
+		group, ok := root[t.group].(map[string]any)
+		if !ok {
+			group = map[string]any{}
+			root[t.group] = group
+		}
+		group[t.name] = token
+	}
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("could not encode tokens: %w", err)
+	}
+	return []exportFile{{exportBaseName(p) + ".tokens.json", append(data, '\n')}}, nil
+}
+
+func exportAndroid(p Project) ([]exportFile, error) {
+	tokens := tokenColors(p)
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString("<resources>\n")
+	for _, t := range tokens {
+		// Android puts the alpha first, as #AARRGGBB
+		value := fmt.Sprintf("#%02X%02X%02X", t.rgba.r, t.rgba.g, t.rgba.b)
+		if t.rgba.a != 255 {
+			value = fmt.Sprintf("#%02X%02X%02X%02X", t.rgba.a, t.rgba.r, t.rgba.g, t.rgba.b)
+		}
+		b.WriteString(`    <color name="`)
+		xml.EscapeText(&b, []byte(strings.ReplaceAll(t.flatName("_"), "-", "_")))
+		fmt.Fprintf(&b, "\">%s</color>\n", value)
+	}
+	b.WriteString("</resources>\n")
+	return []exportFile{{"colors.xml", b.Bytes()}}, nil
+}
+
+// xcassetsInfo is the "info" object every asset catalog Contents.json has.
+var xcassetsInfo = map[string]any{"author": "xcode", "version": 1}
+
+// exportIOS writes an asset catalog with one color set per color, e.g.
+// my-app.xcassets/primary-brand-pink.colorset/Contents.json.
+func exportIOS(p Project) ([]exportFile, error) {
+	tokens := tokenColors(p)
+
+	catalog := exportBaseName(p) + ".xcassets"
+	contents, err := json.MarshalIndent(map[string]any{"info": xcassetsInfo}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("could not encode asset catalog: %w", err)
+	}
+	files := []exportFile{{filepath.Join(catalog, "Contents.json"), append(contents, '\n')}}
+
+	for _, t := range tokens {
+		r, g, b, a := t.rgba.floats()
+		colorSet := map[string]any{
+			"info": xcassetsInfo,
+			"colors": []any{map[string]any{
+				"idiom": "universal",
+				"color": map[string]any{
+					"color-space": "srgb",
+					"components": map[string]string{
+						"red":   fmt.Sprintf("%.3f", r),
+						"green": fmt.Sprintf("%.3f", g),
+						"blue":  fmt.Sprintf("%.3f", b),
+						"alpha": fmt.Sprintf("%.3f", a),
+					},
+				},
+			}},
+		}
+		data, err := json.MarshalIndent(colorSet, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("could not encode color set: %w", err)
+		}
+		path := filepath.Join(catalog, t.flatName("-")+".colorset", "Contents.json")
+		files = append(files, exportFile{path, append(data, '\n')})
+	}
+	return files, nil
+}
+
+// --- FILE I/O ---
+
+// writeExport writes files below dir, creating directories as needed, and
+// returns the top-level paths it wrote.
+func writeExport(dir string, files []exportFile) ([]string, error) {
+	var written []string
+	seen := map[string]bool{}
+	for _, f := range files {
+		path := filepath.Join(dir, f.path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("could not create export directory: %w", err)
+		}
+		if err := writeFileAtomic(path, f.data, 0644); err != nil {
+			return nil, fmt.Errorf("could not write %s: %w", path, err)
+		}
+
+		top := filepath.Join(dir, strings.SplitN(filepath.ToSlash(f.path), "/", 2)[0])
+		if !seen[top] {
+			seen[top] = true
+			written = append(written, top)
+		}
+	}
+	return written, nil
+}
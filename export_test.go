@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var exportProject = Project{
+	Name: "My App",
+	Colors: []namedColor{
+		{Group: "Primary", Name: "Brand Pink", Value: "#FF5F87", Notes: "Buttons"},
+		{Group: "Primary", Name: "Brand Pink", Value: "#FF87AF"},
+		{Value: "#11223380"},
+	},
+}
+
+// exportOutput runs the named export of p and returns its only file.
+func exportOutput(t *testing.T, format string, p Project) string {
+	t.Helper()
+	f, err := findExportFormat(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := f.write(p)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+	return string(files[0].data)
+}
+
+func TestExportFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"css", `/* My App */
+:root {
+  --primary-brand-pink: #FF5F87;
+  --primary-brand-pink-2: #FF87AF;
+  --color-3: #11223380;
+}
+`},
+		{"scss", `// My App
+$primary-brand-pink: #FF5F87;
+$primary-brand-pink-2: #FF87AF;
+$color-3: #11223380;
+`},
+		{"tailwind", `// My App
+module.exports = {
+  theme: {
+    colors: {
+      "color-3": "#11223380",
+      "primary": {
+        "brand-pink": "#FF5F87",
+        "brand-pink-2": "#FF87AF",
+      },
+    },
+  },
+};
+`},
+		{"tokens", `{
+  "color-3": {
+    "$type": "color",
+    "$value": "#11223380"
+  },
+  "primary": {
+    "brand-pink": {
+      "$type": "color",
+      "$value": "#FF5F87",
+      "$description": "Buttons"
+    },
+    "brand-pink-2": {
+      "$type": "color",
+      "$value": "#FF87AF"
+    }
+  }
+}
+`},
+		{"android", `<?xml version="1.0" encoding="UTF-8"?>
+<resources>
+    <color name="primary_brand_pink">#FF5F87</color>
+    <color name="primary_brand_pink_2">#FF87AF</color>
+    <color name="color_3">#80112233</color>
+</resources>
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := exportOutput(t, tt.format, exportProject); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportIOS(t *testing.T) {
+	files, err := exportIOS(exportProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, strings.ReplaceAll(f.path, "\\", "/"))
+	}
+	want := []string{
+		"my-app.xcassets/Contents.json",
+		"my-app.xcassets/primary-brand-pink.colorset/Contents.json",
+		"my-app.xcassets/primary-brand-pink-2.colorset/Contents.json",
+		"my-app.xcassets/color-3.colorset/Contents.json",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got paths %v, want %v", paths, want)
+	}
+
+	var colorSet struct {
+		Colors []struct {
+			Color struct {
+				Components map[string]string `json:"components"`
+			} `json:"color"`
+		} `json:"colors"`
+	}
+	if err := json.Unmarshal(files[3].data, &colorSet); err != nil {
+		t.Fatal(err)
+	}
+	components := colorSet.Colors[0].Color.Components
+	wantComponents := map[string]string{"red": "0.067", "green": "0.133", "blue": "0.200", "alpha": "0.502"}
+	if !reflect.DeepEqual(components, wantComponents) {
+		t.Errorf("got components %v, want %v", components, wantComponents)
+	}
+}
+
+// A flat name of one group can equal the name of another color, and an
+// ungrouped name can equal a group.
+var clashingProject = Project{
+	Name: "Clashes",
+	Colors: []namedColor{
+		{Name: "primary", Value: "#FF0000"},
+		{Group: "primary", Name: "brand", Value: "#00FF00"},
+		{Name: "primary brand", Value: "#0000FF"},
+		{Name: "1", Value: "#111111"},
+		{Name: "color 1", Value: "#222222"},
+	},
+}
+
+func TestExportNamesAreUnique(t *testing.T) {
+	values := []string{"#FF0000", "#00FF00", "#0000FF", "#111111", "#222222"}
+
+	flatFormats := []struct {
+		format string
+		name   *regexp.Regexp
+	}{
+		{"css", regexp.MustCompile(`(?m)^  (--[^:]+): (#\w+);$`)},
+		{"scss", regexp.MustCompile(`(?m)^(\$[^:]+): (#\w+);$`)},
+		{"android", regexp.MustCompile(`<color name="([^"]+)">(#\w+)</color>`)},
+	}
+	for _, tt := range flatFormats {
+		t.Run(tt.format, func(t *testing.T) {
+			seen := map[string]bool{}
+			var got []string
+			for _, match := range tt.name.FindAllStringSubmatch(exportOutput(t, tt.format, clashingProject), -1) {
+				if seen[match[1]] {
+					t.Errorf("name %s is used twice", match[1])
+				}
+				seen[match[1]] = true
+				got = append(got, match[2])
+			}
+			if !reflect.DeepEqual(got, values) {
+				t.Errorf("got values %v, want %v", got, values)
+			}
+		})
+	}
+
+	t.Run("tokens", func(t *testing.T) {
+		var root map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(exportOutput(t, "tokens", clashingProject)), &root); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for key, raw := range root {
+			var token designToken
+			if err := json.Unmarshal(raw, &token); err == nil && token.Value != "" {
+				got = append(got, token.Value)
+				continue
+			}
+			var group map[string]designToken
+			if err := json.Unmarshal(raw, &group); err != nil {
+				t.Fatalf("%s is neither a token nor a group: %v", key, err)
+			}
+			for _, token := range group {
+				got = append(got, token.Value)
+			}
+		}
+		slices.Sort(got)
+		want := slices.Clone(values)
+		slices.Sort(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got values %v, want %v", got, want)
+		}
+	})
+
+	t.Run("tailwind", func(t *testing.T) {
+		out := exportOutput(t, "tailwind", clashingProject)
+		topLevel := regexp.MustCompile(`(?m)^      ("[^"]+"):`)
+		seen := map[string]bool{}
+		for _, match := range topLevel.FindAllStringSubmatch(out, -1) {
+			if seen[match[1]] {
+				t.Errorf("key %s is used twice", match[1])
+			}
+			seen[match[1]] = true
+		}
+		for _, value := range values {
+			if !strings.Contains(out, `"`+value+`"`) {
+				t.Errorf("%s is missing", value)
+			}
+		}
+	})
+}
+
+func TestExportSkipsInvalidColors(t *testing.T) {
+	p := Project{Name: "web", Colors: []namedColor{
+		{Name: "typo", Value: "#GGGGGG"},
+		{Name: "pink", Value: "#FF5F87"},
+	}}
+
+	want := "/* web */\n:root {\n  --pink: #FF5F87;\n}\n"
+	if got := exportOutput(t, "css", p); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got := invalidColors(p); !reflect.DeepEqual(got, []string{"#GGGGGG"}) {
+		t.Errorf("invalidColors = %v, want [#GGGGGG]", got)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Brand Pink", "brand-pink"},
+		{"  Primary / Brand  ", "primary-brand"},
+		{"Grün 500", "grun-500"},
+		{"Crème brûlée", "creme-brulee"},
+		{"Straße", "strasse"},
+		{"赤 Red", "red"},
+		{"赤", ""},
+		{"---", ""},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.in); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExportNamesAreASCII(t *testing.T) {
+	p := Project{Name: "Café", Colors: []namedColor{
+		{Group: "Primär", Name: "Grün", Value: "#00FF00"},
+		{Name: "赤", Value: "#FF0000"},
+	}}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"android", `<?xml version="1.0" encoding="UTF-8"?>
+<resources>
+    <color name="primar_grun">#00FF00</color>
+    <color name="color_2">#FF0000</color>
+</resources>
+`},
+		{"scss", "// Café\n$primar-grun: #00FF00;\n$color-2: #FF0000;\n"},
+		{"css", "/* Café */\n:root {\n  --primar-grun: #00FF00;\n  --color-2: #FF0000;\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := exportOutput(t, tt.format, p); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	files, err := exportSCSS(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files[0].path != "_cafe.scss" {
+		t.Errorf("got file %s, want _cafe.scss", files[0].path)
+	}
+}
@@ -0,0 +1,479 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// --- IMPORT ---
+
+// importColors reads the palette file at path, choosing the parser by its
+// extension. Colors are returned normalized, in file order.
+func importColors(path string) ([]namedColor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	var colors []namedColor
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gpl":
+		colors, err = parseGPL(data)
+	case ".ase":
+		colors, err = parseASE(data)
+	case ".css", ".scss", ".sass", ".less":
+		colors, err = parseCSSColors(data)
+	case ".json":
+		colors, err = parseTokenColors(data)
+	case ".js", ".cjs", ".mjs", ".ts":
+		colors, err = parseTailwindColors(data)
+	case ".png", ".jpg", ".jpeg":
+		colors, err = parseImageColors(data)
+	default:
+		return nil, fmt.Errorf("unsupported palette file %s, expected .gpl, .ase, .css, .json, a Tailwind config or a PNG or JPEG image", filepath.Base(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not import %s: %w", filepath.Base(path), err)
+	}
+	return colors, nil
+}
+
+// dedupeColors returns the imported colors that are neither in existing nor
+// repeated earlier in imported, and how many were skipped.
+func dedupeColors(existing, imported []namedColor) (added []namedColor, skipped int) {
+	seen := map[string]bool{}
+	for _, c := range existing {
+		seen[canonicalColor(c.Value)] = true
+	}
+	for _, c := range imported {
+		key := canonicalColor(c.Value)
+		if seen[key] {
+			skipped++
+			continue
+		}
+		seen[key] = true
+		added = append(added, c)
+	}
+	return added, skipped
+}
+
+// --- IMAGES ---
+
+// maxImagePixels caps the size of images colors are extracted from, as
+// decoding holds every pixel in memory.
+const maxImagePixels = 50_000_000
+
+// parseImageColors returns the dominant colors of a PNG or JPEG image.
+func parseImageColors(data []byte) ([]namedColor, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode image: %w", err)
+	}
+	if int64(config.Width)*int64(config.Height) > maxImagePixels {
+		return nil, fmt.Errorf("image is %dx%d, larger than the %d megapixel limit", config.Width, config.Height, maxImagePixels/1_000_000)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode image: %w", err)
+	}
+
+	var colors []namedColor
+	for _, c := range extractPalette(img, extractedColors) {
+		colors = append(colors, namedColor{Value: c.hex()})
+	}
+	return colors, nil
+}
+
+// --- GIMP PALETTES ---
+
+// parseGPL reads a GIMP palette, which lists one "R G B name" color per line
+// after a "GIMP Palette" header.
+func parseGPL(data []byte) ([]namedColor, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "GIMP Palette" {
+		return nil, errors.New("missing GIMP Palette header")
+	}
+
+	var colors []namedColor
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.Contains(strings.SplitN(line, " ", 2)[0], ":") {
+			// Comments and headers such as "Name: ..." and "Columns: ..."
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("invalid palette line %q", line)
+		}
+		var channels [3]uint8
+		for i := range channels {
+			v, err := strconv.ParseUint(fields[i], 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid palette line %q", line)
+			}
+			channels[i] = uint8(v)
+		}
+
+		name := strings.Join(fields[3:], " ")
+		if name == "Untitled" {
+			name = ""
+		}
+		c := rgba{channels[0], channels[1], channels[2], 255}
+		colors = append(colors, namedColor{Value: c.hex(), Name: name})
+	}
+	return colors, scanner.Err()
+}
+
+// --- ADOBE SWATCH EXCHANGE ---
+
+const (
+	aseGroupStart = 0xC001
+	aseGroupEnd   = 0xC002
+	aseColorEntry = 0x0001
+)
+
+// parseASE reads an Adobe Swatch Exchange file. Swatch groups become color
+// groups.
+func parseASE(data []byte) ([]namedColor, error) {
+	r := bytes.NewReader(data)
+	var header struct {
+		Signature [4]byte
+		Major     uint16
+		Minor     uint16
+		Blocks    uint32
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil || string(header.Signature[:]) != "ASEF" {
+		return nil, errors.New("not an Adobe Swatch Exchange file")
+	}
+
+	var colors []namedColor
+	group := ""
+	for i := uint32(0); i < header.Blocks; i++ {
+		var block struct {
+			Type   uint16
+			Length uint32
+		}
+		if err := binary.Read(r, binary.BigEndian, &block); err != nil {
+			return nil, fmt.Errorf("truncated swatch file: %w", err)
+		}
+		if int64(block.Length) > int64(r.Len()) {
+			return nil, errors.New("truncated swatch file")
+		}
+		body := make([]byte, block.Length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return nil, errors.New("truncated swatch file")
+		}
+
+		switch block.Type {
+		case aseGroupStart:
+			br := bytes.NewReader(body)
+			name, err := readASEString(br)
+			if err != nil {
+				return nil, err
+			}
+			group = name
+		case aseGroupEnd:
+			group = ""
+		case aseColorEntry:
+			c, err := parseASEColor(body)
+			if err != nil {
+				return nil, err
+			}
+			c.Group = group
+			colors = append(colors, c)
+		}
+	}
+	return colors, nil
+}
+
+func parseASEColor(body []byte) (namedColor, error) {
+	r := bytes.NewReader(body)
+	name, err := readASEString(r)
+	if err != nil {
+		return namedColor{}, err
+	}
+	var model [4]byte
+	if _, err := io.ReadFull(r, model[:]); err != nil {
+		return namedColor{}, errors.New("truncated swatch")
+	}
+
+	counts := map[string]int{"RGB ": 3, "CMYK": 4, "LAB ": 3, "Gray": 1}
+	n, ok := counts[string(model[:])]
+	if !ok {
+		return namedColor{}, fmt.Errorf("swatch %q uses the unsupported color model %q", name, string(model[:]))
+	}
+	v := make([]float32, n)
+	if err := binary.Read(r, binary.BigEndian, v); err != nil {
+		return namedColor{}, errors.New("truncated swatch")
+	}
+
+	var red, green, blue float64
+	switch string(model[:]) {
+	case "RGB ":
+		red, green, blue = float64(v[0]), float64(v[1]), float64(v[2])
+	case "CMYK":
+		k := 1 - float64(v[3])
+		red, green, blue = (1-float64(v[0]))*k, (1-float64(v[1]))*k, (1-float64(v[2]))*k
+	case "LAB ":
+		// L is stored as 0-1 rather than 0-100
+		red, green, blue = labToRGB(float64(v[0])*100, float64(v[1]), float64(v[2]))
+	case "Gray":
+		red, green, blue = float64(v[0]), float64(v[0]), float64(v[0])
+	}
+	c := rgba{to8Bit(red), to8Bit(green), to8Bit(blue), 255}
+	return namedColor{Value: c.hex(), Name: name}, nil
+}
+
+// readASEString reads a length-prefixed, NUL-terminated UTF-16 string.
+func readASEString(r *bytes.Reader) (string, error) {
+	var length uint16
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return "", errors.New("truncated swatch name")
+	}
+	units := make([]uint16, length)
+	if err := binary.Read(r, binary.BigEndian, units); err != nil {
+		return "", errors.New("truncated swatch name")
+	}
+	return strings.TrimRight(string(utf16.Decode(units)), "\x00"), nil
+}
+
+// --- STYLESHEETS ---
+
+var (
+	// cssColorLiteral matches hex colors and color functions
+	cssColorLiteral = regexp.MustCompile(`#[0-9A-Fa-f]{3,8}\b|(?:rgba?|hsla?|oklch)\([^)]*\)`)
+	// cssVariable matches the start of a custom property or SCSS/Less variable declaration
+	cssVariable = regexp.MustCompile(`^\s*(?:--|\$|@)([\w-]+)\s*:`)
+)
+
+// parseCSSColors scans a stylesheet for color literals in declarations. A
+// color declared as the only value of a variable is named after it.
+func parseCSSColors(data []byte) ([]namedColor, error) {
+	var colors []namedColor
+	for _, line := range strings.Split(string(data), "\n") {
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		literals := cssColorLiteral.FindAllString(line[i+1:], -1)
+
+		name := ""
+		if match := cssVariable.FindStringSubmatch(line); match != nil && len(literals) == 1 {
+			name = match[1]
+		}
+		for _, literal := range literals {
+			value, err := normalizeColor(literal)
+			if err != nil {
+				// e.g. an ID selector or a function using variables
+				continue
+			}
+			colors = append(colors, namedColor{Value: value, Name: name})
+		}
+	}
+	return colors, nil
+}
+
+// --- DESIGN TOKENS ---
+
+// parseTokenColors reads a W3C Design Tokens file. Color tokens are the ones
+// with a $type of "color", given on the token or a group above it, or whose
+// value is a color. Tokens nested in a group take the outermost group as
+// their group and the rest of their path as their name.
+func parseTokenColors(data []byte) ([]namedColor, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var colors []namedColor
+	if err := walkTokens(dec, nil, "", &colors); err != nil {
+		return nil, err
+	}
+	return colors, nil
+}
+
+// walkTokens reads one JSON value from dec, keeping the order of object keys,
+// which encoding/json maps lose.
+func walkTokens(dec *json.Decoder, path []string, inheritedType string, colors *[]namedColor) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("invalid tokens file: %w", err)
+	}
+	if tok != json.Delim('{') {
+		return skipJSONValue(dec, tok)
+	}
+
+	// Read the keys of this object, descending into nested objects right away
+	var value, description string
+	tokenType := inheritedType
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("invalid tokens file: %w", err)
+		}
+		key := keyTok.(string)
+		childPath := append(path[:len(path):len(path)], key)
+
+		switch key {
+		case "$value", "value", "$type", "type", "$description", "description":
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return fmt.Errorf("invalid tokens file: %w", err)
+			}
+			// Without the $ prefix these may also name a group, e.g. "type"
+			// for typography, so only scalars count as properties
+			if !strings.HasPrefix(key, "$") && (raw[0] == '{' || raw[0] == '[') {
+				if err := walkTokens(json.NewDecoder(bytes.NewReader(raw)), childPath, tokenType, colors); err != nil {
+					return err
+				}
+				continue
+			}
+
+			switch strings.TrimPrefix(key, "$") {
+			case "value":
+				if err := json.Unmarshal(raw, &value); err != nil {
+					// Composite values such as shadows are not colors
+					value = ""
+				}
+			case "type":
+				if err := json.Unmarshal(raw, &tokenType); err != nil {
+					return fmt.Errorf("invalid tokens file: %w", err)
+				}
+			case "description":
+				if err := json.Unmarshal(raw, &description); err != nil {
+					description = ""
+				}
+			}
+		default:
+			if strings.HasPrefix(key, "$") {
+				var ignored json.RawMessage
+				if err := dec.Decode(&ignored); err != nil {
+					return fmt.Errorf("invalid tokens file: %w", err)
+				}
+				continue
+			}
+			// Groups may set a $type after their tokens, which is rare
+			// enough to not be worth a second pass
+			if err := walkTokens(dec, childPath, tokenType, colors); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("invalid tokens file: %w", err)
+	}
+
+	if value == "" || len(path) == 0 || (tokenType != "" && tokenType != "color") {
+		return nil
+	}
+	normalized, err := normalizeColor(value)
+	if err != nil {
+		// e.g. an alias such as "{color.primary}"
+		return nil
+	}
+	*colors = append(*colors, colorFromPath(path, normalized, description))
+	return nil
+}
+
+// skipJSONValue consumes the rest of the value starting with tok.
+func skipJSONValue(dec *json.Decoder, tok json.Token) error {
+	if tok != json.Delim('[') {
+		return nil
+	}
+	for dec.More() {
+		next, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("invalid tokens file: %w", err)
+		}
+		if err := skipJSONValue(dec, next); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// colorFromPath names a color found at path in a nested structure, e.g.
+// ["primary", "brand-pink"] becomes "brand-pink" in the group "primary".
+func colorFromPath(path []string, value, notes string) namedColor {
+	c := namedColor{Value: value, Notes: notes, Name: path[len(path)-1]}
+	if len(path) > 1 {
+		c.Group = path[0]
+		c.Name = strings.Join(path[1:], "-")
+	}
+	return c
+}
+
+// --- TAILWIND ---
+
+var (
+	// tailwindToken matches an object key followed by a string or an
+	// opening brace, or a brace on its own
+	tailwindToken = regexp.MustCompile(`(?:['"]?([\w-]+)['"]?\s*:\s*)?(\{|\}|'[^'\n]*'|"[^"\n]*")`)
+	// tailwindWrappers are the config keys colors are nested in
+	tailwindWrappers = map[string]bool{"module": true, "theme": true, "extend": true, "colors": true}
+)
+
+// parseTailwindColors scans a Tailwind config for string values that are
+// colors, without evaluating it. Nested objects such as pink: {500: ...}
+// become groups, and a DEFAULT key takes the name of its object.
+func parseTailwindColors(data []byte) ([]namedColor, error) {
+	var colors []namedColor
+	var stack []string
+	for _, match := range tailwindToken.FindAllStringSubmatch(string(data), -1) {
+		key, token := match[1], match[2]
+		switch token {
+		case "{":
+			stack = append(stack, key)
+			continue
+		case "}":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+		if key == "" {
+			continue
+		}
+		value, err := normalizeColor(token[1 : len(token)-1])
+		if err != nil {
+			continue
+		}
+
+		var path []string
+		for _, k := range stack {
+			if k != "" && !tailwindWrappers[k] {
+				path = append(path, k)
+			}
+		}
+		if key != "DEFAULT" || len(path) == 0 {
+			path = append(path, key)
+		}
+		colors = append(colors, colorFromPath(path, value, ""))
+	}
+	return colors, nil
+}
+
+// expandHome replaces a leading ~ in path with the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestParseTokenColors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []namedColor
+	}{
+		{
+			name: "W3C tokens with an inherited type",
+			in:   `{"primary": {"$type": "color", "brand": {"$value": "#ff5f87", "$description": "Buttons"}}, "spacing": {"$type": "dimension", "small": {"$value": "4px"}}}`,
+			want: []namedColor{{Group: "primary", Name: "brand", Value: "#FF5F87", Notes: "Buttons"}},
+		},
+		{
+			name: "legacy tokens without the $ prefix",
+			in:   `{"pink": {"type": "color", "value": "rgb(255, 95, 135)", "description": "Pink"}}`,
+			want: []namedColor{{Name: "pink", Value: "#FF5F87", Notes: "Pink"}},
+		},
+		{
+			name: "group called type",
+			in:   `{"type": {"heading": {"$type": "fontFamily", "$value": "Inter"}}, "pink": {"$value": "#FF5F87"}}`,
+			want: []namedColor{{Name: "pink", Value: "#FF5F87"}},
+		},
+		{
+			name: "group called value",
+			in:   `{"value": {"positive": {"$type": "color", "$value": "#00FF00"}}}`,
+			want: []namedColor{{Group: "value", Name: "positive", Value: "#00FF00"}},
+		},
+		{
+			name: "group called description",
+			in:   `{"brand": {"description": {"text": {"$value": "#333333"}}}}`,
+			want: []namedColor{{Group: "brand", Name: "description-text", Value: "#333333"}},
+		},
+		{
+			name: "aliases and composite values are skipped",
+			in:   `{"primary": {"$value": "{color.pink}"}, "shadow": {"$type": "shadow", "$value": {"color": "#000000"}}}`,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTokenColors([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// aseString encodes s as a length-prefixed, NUL-terminated UTF-16 string.
+func aseString(s string) []byte {
+	units := append(utf16.Encode([]rune(s)), 0)
+	b := binary.BigEndian.AppendUint16(nil, uint16(len(units)))
+	for _, u := range units {
+		b = binary.BigEndian.AppendUint16(b, u)
+	}
+	return b
+}
+
+func aseBlock(blockType uint16, body []byte) []byte {
+	b := binary.BigEndian.AppendUint16(nil, blockType)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(body)))
+	return append(b, body...)
+}
+
+func aseColor(name, model string, values ...float32) []byte {
+	body := append(aseString(name), model...)
+	for _, v := range values {
+		body = binary.BigEndian.AppendUint32(body, math.Float32bits(v))
+	}
+	// Color type: global
+	body = binary.BigEndian.AppendUint16(body, 0)
+	return aseBlock(aseColorEntry, body)
+}
+
+// aseFile prefixes blocks with a header declaring count blocks.
+func aseFile(count int, blocks ...[]byte) []byte {
+	b := []byte("ASEF")
+	b = binary.BigEndian.AppendUint16(b, 1)
+	b = binary.BigEndian.AppendUint16(b, 0)
+	b = binary.BigEndian.AppendUint32(b, uint32(count))
+	for _, block := range blocks {
+		b = append(b, block...)
+	}
+	return b
+}
+
+func TestParseASE(t *testing.T) {
+	blocks := [][]byte{
+		aseBlock(aseGroupStart, aseString("Brand")),
+		aseColor("Blue", "RGB ", 0, 0, 1),
+		aseColor("Red", "CMYK", 0, 1, 1, 0),
+		aseBlock(aseGroupEnd, nil),
+		aseColor("Mid gray", "LAB ", 0.5, 0, 0),
+		aseColor("Gray", "Gray", 0.5),
+	}
+	got, err := parseASE(aseFile(len(blocks), blocks...))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	want := []namedColor{
+		{Group: "Brand", Name: "Blue", Value: "#0000FF"},
+		{Group: "Brand", Name: "Red", Value: "#FF0000"},
+		{Name: "Mid gray", Value: "#777777"},
+		{Name: "Gray", Value: "#808080"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseASEErrors(t *testing.T) {
+	blue := aseColor("Blue", "RGB ", 0, 0, 1)
+	tooLong := slices.Clone(blue)
+	binary.BigEndian.PutUint32(tooLong[2:], 1000)
+
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{"not a swatch file", []byte("GIMP Palette\n"), "not an Adobe Swatch Exchange file"},
+		{"missing block", aseFile(2, blue), "truncated swatch file"},
+		{"truncated block header", aseFile(1, blue[:4]), "truncated swatch file"},
+		{"declared length too large", aseFile(1, tooLong), "truncated swatch file"},
+		{"truncated values", aseFile(1, aseBlock(aseColorEntry, append(aseString("Blue"), "RGB "...))), "truncated swatch"},
+		{"unsupported model", aseFile(1, aseColor("Spot", "HSB ", 0, 0, 0)), "unsupported color model"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseASE(tt.in)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGPL(t *testing.T) {
+	in := `GIMP Palette
+Name: Brand
+Columns: 4
+#
+# Exported from GIMP
+255  95 135	Pink
+  0   0 255	Untitled
+ 17  34  51	Dark blue gray
+`
+	got, err := parseGPL([]byte(in))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	want := []namedColor{
+		{Name: "Pink", Value: "#FF5F87"},
+		{Value: "#0000FF"},
+		{Name: "Dark blue gray", Value: "#112233"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := parseGPL([]byte("255 95 135 Pink\n")); err == nil {
+		t.Error("parsed a palette without a header")
+	}
+	if _, err := parseGPL([]byte("GIMP Palette\n256 0 0 Too red\n")); err == nil {
+		t.Error("parsed a channel above 255")
+	}
+}
+
+func TestParseCSSColors(t *testing.T) {
+	in := `:root {
+  --pink: rgb(255, 95, 135);
+  --accent: hsl(240, 100%, 50%);
+  $border: #abc;
+}
+#header { color: var(--pink); }
+.overlay {
+  background: linear-gradient(#000, rgba(255, 255, 255, 0.5));
+}
+`
+	got, err := parseCSSColors([]byte(in))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	want := []namedColor{
+		{Name: "pink", Value: "#FF5F87"},
+		{Name: "accent", Value: "#0000FF"},
+		{Name: "border", Value: "#AABBCC"},
+		{Value: "#000000"},
+		{Value: "#FFFFFF80"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseTailwindColors(t *testing.T) {
+	in := `module.exports = {
+  theme: {
+    extend: {
+      colors: {
+        pink: {
+          DEFAULT: '#FF5F87',
+          500: "#ff5f87",
+        },
+        'brand-blue': '#0000FF',
+        font: 'Inter',
+      },
+    },
+  },
+}
+`
+	got, err := parseTailwindColors([]byte(in))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	want := []namedColor{
+		{Name: "pink", Value: "#FF5F87"},
+		{Group: "pink", Name: "500", Value: "#FF5F87"},
+		{Name: "brand-blue", Value: "#0000FF"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDedupeColors(t *testing.T) {
+	// Stored by an older version, before values were normalized
+	existing := []namedColor{{Name: "pink", Value: "#ff5f87"}}
+	imported := []namedColor{
+		{Name: "Pink", Value: "#FF5F87"},
+		{Name: "Blue", Value: "#0000FF"},
+		{Name: "Blue again", Value: "#0000FF"},
+		{Name: "Veil", Value: "#FFFFFF80"},
+	}
+
+	added, skipped := dedupeColors(existing, imported)
+	want := []namedColor{{Name: "Blue", Value: "#0000FF"}, {Name: "Veil", Value: "#FFFFFF80"}}
+	if !reflect.DeepEqual(added, want) || skipped != 2 {
+		t.Errorf("got %+v and %d skipped, want %+v and 2 skipped", added, skipped, want)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const librariesFileName = "libraries.json"
+
+// defaultLibraryName refers to the data file chosen at startup, i.e. --data,
+// DIAMONDS_DATA or the default location.
+const defaultLibraryName = "default"
+
+// --- DATA STRUCTURES ---
+
+// library is a named data file, e.g. a team library kept in a git repository
+// next to the personal one.
+type library struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+type librariesFile struct {
+	Libraries []library `json:"libraries"`
+}
+
+// --- FILE I/O ---
+
+func getLibrariesFilePath() (string, error) {
+	configDir, err := getAppConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, librariesFileName), nil
+}
+
+// loadLibraries returns the registered libraries, not including the default one.
+func loadLibraries() ([]library, error) {
+	path, err := getLibrariesFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []library{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read libraries file: %w", err)
+	}
+
+	var file librariesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("could not parse libraries file: %w", err)
+	}
+	return file.Libraries, nil
+}
+
+func writeLibraries(libraries []library) error {
+	path, err := getLibrariesFilePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(librariesFile{Libraries: libraries}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode libraries: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("could not write libraries file: %w", err)
+	}
+	return nil
+}
+
+// findLibrary returns the index of the library called name, or -1.
+func findLibrary(libraries []library, name string) int {
+	for i, l := range libraries {
+		if l.Name == name {
+			return i
+		}
+	}
+	return -1
+}
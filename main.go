@@ -1,9 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/atotto/clipboard"
@@ -19,12 +22,61 @@ type model struct {
 	currentView     ViewState
 	cursor          int
 	selectedProject int
-	inputBuffer     string // Used for single-line inputs
-	urlNameBuffer   string // Used for the URL name in AddUrlView
-	focusedField    int    // Used in AddUrlView to track focus
+	inputBuffer     string                  // Used for single-line inputs
+	focusedField    int                     // Used in AddUrlView and AddColorView to track focus
+	colorFields     [colorFieldCount]string // Used in AddColorView
+	urlFields       [urlFieldCount]string   // Used in AddUrlView
+	inputError      string                  // Validation error shown inline in forms
 	message         string
+	recoveryNote    string       // Explains why RecoveryView is shown
+	backups         []backupFile // Backups offered in RecoveryView
+	dataStamp       dataStamp    // Version of the data file we last loaded or saved
+	baseProjects    []Project    // Projects as of dataStamp, used to merge concurrent edits
+	libraryName     string       // Name of the open library
+	defaultDataPath string       // Data file chosen at startup, i.e. the default library
+	libraries       []library    // Libraries offered in LibraryListView
+	overlay         *repoOverlay // Project from the repository's .diamonds.json, if any
+	settings        settings     // Per-user preferences
+	urlUsage        libraryUsage // When URLs of the open library were last used
+	pickerColor     namedColor   // Color being copied in ColorFormatView
+	formatCursor    int          // Selected format in ColorFormatView
+	previousView    ViewState    // View to return to from ColorFormatView
+	contrastText    int          // Index of the text color in ContrastView
+	contrastBg      int          // Index of the background color in ContrastView
+	contrastMatrix  bool         // Whether ContrastView shows every pair
+	palette         []paletteEntry
+	paletteBase     namedColor // Color the palette was generated from
+	paletteCursor   int
+	paletteSelected map[int]bool // Palette entries to add to the project
+	exportCursor    int
+	importPath      string           // File the colors in ImportPreviewView come from
+	imported        []namedColor     // New colors awaiting confirmation
+	importSkipped   int              // Imported colors that already exist
+	importSelected  map[int]bool     // Imported colors to add to the project
+	simulation      visionDeficiency // Deficiency swatches are drawn for
+	width, height   int              // Terminal size from the last tea.WindowSizeMsg
 }
 
+// Fields of AddUrlView, in tab order
+const (
+	urlNameField = iota
+	urlAddressField
+	urlDescriptionField
+	urlEnvironmentField
+	urlTagsField
+	urlFieldCount
+)
+
+// Fields of AddColorView, in tab order
+const (
+	colorValueField = iota
+	colorNameField
+	colorGroupField
+	colorNotesField
+	colorTagsField
+	colorFieldCount
+)
+
 // --- HELPER FUNCTIONS ---
 
 // deleteLastRune removes the last character from a string, handling unicode characters correctly.
@@ -33,15 +85,69 @@ func deleteLastRune(s string) string {
 	return s[:len(s)-size]
 }
 
+// replaceProjects swaps in a new set of projects, e.g. after merging or
+// reloading, and keeps the selected project and cursor where possible.
+func (m *model) replaceProjects(projects []Project) {
+	selectedName := ""
+	if m.selectedProject >= 0 && m.selectedProject < len(m.projects) {
+		selectedName = m.projects[m.selectedProject].Name
+	}
+	m.projects = projects
+
+	i := findProject(projects, selectedName)
+	if i < 0 {
+		// The selected project is gone, so views showing it must be left
+		m.selectedProject = 0
+		switch m.currentView {
+		case ProjectListView, AddProjectView, RecoveryView:
+		default:
+			m.currentView = ProjectListView
+			m.cursor = 0
+		}
+		return
+	}
+	m.selectedProject = i
+
+	var count int
+	switch m.currentView {
+	case ColorListView, ContrastView:
+		count = len(projects[i].Colors)
+		m.contrastText = max(0, min(m.contrastText, count-1))
+		m.contrastBg = max(0, min(m.contrastBg, count-1))
+	case UrlListView:
+		count = len(projects[i].Urls)
+	default:
+		return
+	}
+	if m.cursor >= count {
+		m.cursor = max(count-1, 0)
+	}
+}
+
 // --- INITIALIZATION ---
 
 func initialModel() model {
-	loadedProjects, err := loadProjects()
+	loadedProjects, stamp, err := loadProjectsStamped()
+	var recoveryNote string
+	var backups []backupFile
+	if errors.Is(err, errCorruptData) {
+		loadedProjects = []Project{}
+		recoveryNote, backups, err = recoverDataFile(err)
+		// The corrupt file has been moved aside, so there is no file anymore
+		stamp = dataStamp{}
+	}
 	if err != nil {
 		fmt.Printf("Error loading projects: %v\n", err)
 		os.Exit(1)
 	}
 
+	var overlay *repoOverlay
+	cwd, overlayErr := os.Getwd()
+	if overlayErr == nil {
+		overlay, overlayErr = findRepoOverlay(cwd)
+	}
+	loadedProjects = overlay.apply(loadedProjects)
+
 	items := make([]list.Item, len(loadedProjects))
 	for i, project := range loadedProjects {
 		items[i] = &projectItem{project: project}
@@ -49,22 +155,53 @@ func initialModel() model {
 
 	delegate := newCustomDelegate()
 	l := list.New(items, delegate, 0, 0)
-	l.Title = "🪩 DIAMONDS "
+	l.Title = libraryTitle(defaultLibraryName)
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(true)
 	l.Styles.Title = headerStyle.MarginTop(0).PaddingTop(1)
 	l.Styles.HelpStyle = helpStyle
 	l.SetShowHelp(false)
 
-	return model{
-		projectList: l,
-		projects:    loadedProjects,
-		currentView: ProjectListView,
+	userSettings, settingsErr := loadSettings()
+
+	defaultDataPath, err := getDataFilePath()
+	if err != nil {
+		fmt.Printf("Error loading projects: %v\n", err)
+		os.Exit(1)
+	}
+
+	m := model{
+		projectList:     l,
+		projects:        loadedProjects,
+		currentView:     ProjectListView,
+		recoveryNote:    recoveryNote,
+		backups:         backups,
+		dataStamp:       stamp,
+		baseProjects:    cloneProjects(loadedProjects),
+		libraryName:     defaultLibraryName,
+		defaultDataPath: defaultDataPath,
+		overlay:         overlay,
+		settings:        userSettings,
+		urlUsage:        libraryURLUsage(defaultDataPath),
+	}
+	if overlayErr != nil {
+		m.message = fmt.Sprintf("Ignoring %s: %v", repoFileName, overlayErr)
+	}
+	if settingsErr != nil {
+		m.message = fmt.Sprintf("Ignoring settings: %v", settingsErr)
+	}
+	if recoveryNote != "" {
+		m.currentView = RecoveryView
+	} else if overlay != nil {
+		// Launched inside a repository, so open its project straight away
+		m.selectedProject = findProject(m.projects, overlay.project.Name)
+		m.currentView = ProjectMenuView
 	}
+	return m
 }
 
 func (m *model) Init() tea.Cmd {
-	return nil
+	return watchDataFile(m.dataStamp)
 }
 
 // --- UPDATE LOOP ---
@@ -76,12 +213,30 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width, m.height = msg.Width, msg.Height
 		h, v := docStyle.GetHorizontalPadding(), docStyle.GetVerticalPadding()
 		m.projectList.SetSize(msg.Width-h, msg.Height-v)
 		return m, nil
 	}
 
 	switch msg := msg.(type) {
+	case watchTickMsg:
+		return m, watchDataFile(m.dataStamp)
+	case dataReloadMsg:
+		cmd := m.reloadProjects(msg)
+		return m, tea.Batch(cmd, watchDataFile(m.dataStamp))
+	case urlsOpenedMsg:
+		switch {
+		case msg.err != nil && msg.count > 0:
+			m.message = fmt.Sprintf("Opened %s, then: %v", pluralize(msg.count, "URL", "URLs"), msg.err)
+		case msg.err != nil:
+			m.message = fmt.Sprintf("Error opening URL: %v", msg.err)
+		case msg.count == 1:
+			m.message = "Opened URL in the browser"
+		default:
+			m.message = fmt.Sprintf("Opened %s in the browser", pluralize(msg.count, "URL", "URLs"))
+		}
+		return m, nil
 	case tea.KeyMsg:
 		switch m.currentView {
 		case ProjectListView:
@@ -100,6 +255,24 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateAddUrl(msg)
 		case ConfirmDeleteProjectView:
 			return m.updateConfirmDeleteProject(msg)
+		case RecoveryView:
+			return m.updateRecovery(msg)
+		case LibraryListView:
+			return m.updateLibraryList(msg)
+		case ColorFormatView:
+			return m.updateColorFormat(msg)
+		case ContrastView:
+			return m.updateContrast(msg)
+		case GeneratePaletteView:
+			return m.updateGeneratePalette(msg)
+		case ExportView:
+			return m.updateExport(msg)
+		case ImportView:
+			return m.updateImport(msg)
+		case ImportPreviewView:
+			return m.updateImportPreview(msg)
+		case PalettePreviewView:
+			return m.updatePalettePreview(msg)
 		}
 	}
 
@@ -140,16 +313,32 @@ func (m *model) updateProjectList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					}
 				}
 			case *colorItem:
-				clipboard.WriteAll(item.color)
-				m.message = fmt.Sprintf(" Copied %s to clipboard! ", item.color)
+				m.copyColor(item.color, m.settings.copyFormat())
 			case *urlItem:
 				clipboard.WriteAll(item.url.URL)
 				m.message = fmt.Sprintf(" Copied %s to clipboard! ", item.url.URL)
+				m.markURLUsed(item.project, item.url)
 			}
 			return m, nil
 		}
 	}
 
+	// Open a URL found by search
+	if msg.String() == "ctrl+o" {
+		if item, ok := m.projectList.SelectedItem().(*urlItem); ok {
+			m.markURLUsed(item.project, item.url)
+			return m, openURLs(m.settings.Opener, []string{item.url.URL})
+		}
+	}
+
+	// Pick the format to copy a color found by search in
+	if msg.String() == "ctrl+f" {
+		if item, ok := m.projectList.SelectedItem().(*colorItem); ok {
+			m.openColorFormats(item.color)
+			return m, nil
+		}
+	}
+
 	// Application keys (only when not filtering)
 	if m.projectList.FilterState() == list.Unfiltered {
 		switch msg.String() {
@@ -159,6 +348,16 @@ func (m *model) updateProjectList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.currentView = AddProjectView
 			m.inputBuffer = ""
 			return m, nil
+		case "L":
+			libraries, err := loadLibraries()
+			if err != nil {
+				m.message = fmt.Sprintf("Error loading libraries: %v", err)
+				return m, nil
+			}
+			m.libraries = append([]library{{Name: defaultLibraryName, Path: m.defaultDataPath}}, libraries...)
+			m.cursor = max(findLibrary(m.libraries, m.libraryName), 0)
+			m.currentView = LibraryListView
+			return m, nil
 		case "d":
 			selectedItem, ok := m.projectList.SelectedItem().(*projectItem)
 			if ok {
@@ -230,30 +429,71 @@ func (m *model) updateColorList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	case "enter":
 		if len(m.projects[m.selectedProject].Colors) > 0 {
-			color := m.projects[m.selectedProject].Colors[m.cursor]
-			err := clipboard.WriteAll(color)
+			m.copyColor(m.projects[m.selectedProject].Colors[m.cursor], m.settings.copyFormat())
+		}
+	case "f":
+		if len(m.projects[m.selectedProject].Colors) > 0 {
+			m.openColorFormats(m.projects[m.selectedProject].Colors[m.cursor])
+		}
+	case "c":
+		if len(m.projects[m.selectedProject].Colors) > 0 {
+			m.contrastText = m.cursor
+			m.contrastBg = 0
+			if m.cursor == 0 && len(m.projects[m.selectedProject].Colors) > 1 {
+				m.contrastBg = 1
+			}
+			m.contrastMatrix = false
+			m.currentView = ContrastView
+		}
+	case "v":
+		m.simulation = (m.simulation + 1) % (achromatopsia + 1)
+	case "p":
+		if len(m.projects[m.selectedProject].Colors) > 0 {
+			m.currentView = PalettePreviewView
+		}
+	case "i":
+		m.inputBuffer = ""
+		m.inputError = ""
+		m.currentView = ImportView
+	case "e":
+		if len(m.projects[m.selectedProject].Colors) > 0 {
+			m.exportCursor = 0
+			m.currentView = ExportView
+		}
+	case "g":
+		if len(m.projects[m.selectedProject].Colors) > 0 {
+			base := m.projects[m.selectedProject].Colors[m.cursor]
+			palette, err := generatePalette(base)
 			if err != nil {
-				m.message = fmt.Sprintf("Error copying to clipboard: %v", err)
-			} else {
-				m.message = fmt.Sprintf(" Copied %s to clipboard! ", color)
+				m.message = fmt.Sprintf("Cannot generate from %s: %v", base.Value, err)
+				return m, nil
 			}
+			m.palette = palette
+			m.paletteBase = base
+			m.paletteCursor = 0
+			m.paletteSelected = map[int]bool{}
+			m.currentView = GeneratePaletteView
 		}
 	case "d":
 		if len(m.projects[m.selectedProject].Colors) > 0 {
 			deletedColor := m.projects[m.selectedProject].Colors[m.cursor]
+			if m.overlay.hasColor(m.projects[m.selectedProject].Name, deletedColor) {
+				m.message = fmt.Sprintf("%s comes from %s, edit that file to remove it", deletedColor.label(), m.overlay.path)
+				return m, nil
+			}
 			m.projects[m.selectedProject].Colors = append(m.projects[m.selectedProject].Colors[:m.cursor], m.projects[m.selectedProject].Colors[m.cursor+1:]...)
-			cmd := m.updateProjectListItems()
-			m.saveProjects()
-			m.message = fmt.Sprintf("Deleted color %s", deletedColor)
+			m.message = fmt.Sprintf("Deleted color %s", deletedColor.label())
 
 			if m.cursor > 0 && m.cursor >= len(m.projects[m.selectedProject].Colors) {
 				m.cursor--
 			}
-			return m, cmd
+			m.saveProjects()
+			return m, m.updateProjectListItems()
 		}
 	case "n":
 		m.currentView = AddColorView
-		m.inputBuffer = ""
+		m.colorFields = [colorFieldCount]string{}
+		m.focusedField = colorValueField
 	}
 	return m, nil
 }
@@ -278,28 +518,48 @@ func (m *model) updateUrlList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			err := clipboard.WriteAll(url)
 			if err != nil {
 				m.message = fmt.Sprintf("Error copying to clipboard: %v", err)
-			} else {
-				m.message = fmt.Sprintf(" Copied %s to clipboard! ", url)
+				return m, nil
 			}
+			m.message = fmt.Sprintf(" Copied %s to clipboard! ", url)
+			m.markURLUsed(m.projects[m.selectedProject].Name, m.projects[m.selectedProject].Urls[m.cursor])
+			return m, m.updateProjectListItems()
+		}
+	case "o":
+		if len(m.projects[m.selectedProject].Urls) > 0 {
+			u := m.projects[m.selectedProject].Urls[m.cursor]
+			m.markURLUsed(m.projects[m.selectedProject].Name, u)
+			return m, tea.Batch(m.updateProjectListItems(), openURLs(m.settings.Opener, []string{u.URL}))
+		}
+	case "O":
+		if len(m.projects[m.selectedProject].Urls) > 0 {
+			project := m.projects[m.selectedProject]
+			urls := make([]string, len(project.Urls))
+			for i, u := range project.Urls {
+				urls[i] = u.URL
+			}
+			m.markURLUsed(project.Name, project.Urls...)
+			return m, tea.Batch(m.updateProjectListItems(), openURLs(m.settings.Opener, urls))
 		}
 	case "d":
 		if len(m.projects[m.selectedProject].Urls) > 0 {
 			deletedUrl := m.projects[m.selectedProject].Urls[m.cursor].Name
+			if m.overlay.hasURL(m.projects[m.selectedProject].Name, m.projects[m.selectedProject].Urls[m.cursor]) {
+				m.message = fmt.Sprintf("'%s' comes from %s, edit that file to remove it", deletedUrl, m.overlay.path)
+				return m, nil
+			}
 			m.projects[m.selectedProject].Urls = append(m.projects[m.selectedProject].Urls[:m.cursor], m.projects[m.selectedProject].Urls[m.cursor+1:]...)
-			cmd := m.updateProjectListItems()
-			m.saveProjects()
 			m.message = fmt.Sprintf("Deleted URL '%s'", deletedUrl)
 
 			if m.cursor > 0 && m.cursor >= len(m.projects[m.selectedProject].Urls) {
 				m.cursor--
 			}
-			return m, cmd
+			m.saveProjects()
+			return m, m.updateProjectListItems()
 		}
 	case "n":
 		m.currentView = AddUrlView
-		m.inputBuffer = ""
-		m.urlNameBuffer = ""
-		m.focusedField = 0
+		m.urlFields = [urlFieldCount]string{}
+		m.focusedField = urlNameField
 	}
 	return m, nil
 }
@@ -313,12 +573,11 @@ func (m *model) updateAddProject(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.inputBuffer = ""
 	case "enter":
 		if m.inputBuffer != "" {
-			m.projects = append(m.projects, Project{Name: m.inputBuffer, Colors: []string{}, Urls: []namedURL{}})
-			cmd := m.updateProjectListItems()
-			m.saveProjects()
+			m.projects = append(m.projects, Project{Name: m.inputBuffer, Colors: []namedColor{}, Urls: []namedURL{}})
 			m.currentView = ProjectListView
 			m.inputBuffer = ""
-			return m, cmd
+			m.saveProjects()
+			return m, m.updateProjectListItems()
 		}
 	case "backspace":
 		m.inputBuffer = deleteLastRune(m.inputBuffer)
@@ -338,22 +597,68 @@ func (m *model) updateAddColor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 	case "esc":
 		m.currentView = ColorListView
-		m.inputBuffer = ""
+		m.colorFields = [colorFieldCount]string{}
+		m.focusedField = 0
+		m.inputError = ""
 	case "enter":
-		if m.inputBuffer != "" && strings.HasPrefix(m.inputBuffer, "#") && (len(m.inputBuffer) == 7 || len(m.inputBuffer) == 4) {
-			m.projects[m.selectedProject].Colors = append(m.projects[m.selectedProject].Colors, m.inputBuffer)
-			cmd := m.updateProjectListItems()
-			m.saveProjects()
+		value, err := normalizeColor(m.colorFields[colorValueField])
+		if err != nil {
+			m.inputError = fmt.Sprintf("Invalid color: %v", err)
+			m.focusedField = colorValueField
+			return m, nil
+		}
+		newColor := namedColor{
+			Value: value,
+			Name:  m.colorFields[colorNameField],
+			Group: m.colorFields[colorGroupField],
+			Notes: m.colorFields[colorNotesField],
+			Tags:  parseTags(m.colorFields[colorTagsField]),
+		}
+		m.projects[m.selectedProject].Colors = append(m.projects[m.selectedProject].Colors, newColor)
+		m.currentView = ColorListView
+		m.cursor = len(m.projects[m.selectedProject].Colors) - 1
+		m.colorFields = [colorFieldCount]string{}
+		m.focusedField = 0
+		m.inputError = ""
+		m.saveProjects()
+		return m, m.updateProjectListItems()
+	case "ctrl+r":
+		similar := m.similarColors()
+		if len(similar) == 0 {
+			return m, nil
+		}
+		closest := similar[0]
+		if closest.project == m.projects[m.selectedProject].Name {
+			// Already here, so select it instead of adding it again
 			m.currentView = ColorListView
-			m.cursor = len(m.projects[m.selectedProject].Colors) - 1
-			m.inputBuffer = ""
-			return m, cmd
+			m.cursor = closest.index
+			m.colorFields = [colorFieldCount]string{}
+			m.focusedField = 0
+			m.inputError = ""
+			m.message = fmt.Sprintf("%s is already in %s", closest.color.label(), closest.project)
+			return m, nil
+		}
+		m.colorFields = [colorFieldCount]string{
+			colorValueField: closest.color.Value,
+			colorNameField:  closest.color.Name,
+			colorGroupField: closest.color.Group,
+			colorNotesField: closest.color.Notes,
+			colorTagsField:  strings.Join(closest.color.Tags, ", "),
 		}
 	case "backspace":
-		m.inputBuffer = deleteLastRune(m.inputBuffer)
+		m.colorFields[m.focusedField] = deleteLastRune(m.colorFields[m.focusedField])
+		m.inputError = ""
+	case "tab":
+		m.focusedField = (m.focusedField + 1) % colorFieldCount
+	case "shift+tab":
+		m.focusedField = (m.focusedField + colorFieldCount - 1) % colorFieldCount
+	case " ":
+		m.colorFields[m.focusedField] += " "
+		m.inputError = ""
 	default:
-		if msg.Type == tea.KeyRunes && len(m.inputBuffer) < 7 {
-			m.inputBuffer += string(msg.Runes)
+		if msg.Type == tea.KeyRunes {
+			m.colorFields[m.focusedField] += string(msg.Runes)
+			m.inputError = ""
 		}
 	}
 	return m, nil
@@ -365,46 +670,44 @@ func (m *model) updateAddUrl(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 	case "esc":
 		m.currentView = UrlListView
-		m.urlNameBuffer = ""
-		m.inputBuffer = ""
+		m.urlFields = [urlFieldCount]string{}
 		m.focusedField = 0
 	case "enter":
-		if m.focusedField == 0 {
-			m.focusedField = 1
-		} else {
-			if m.urlNameBuffer != "" && m.inputBuffer != "" {
-				m.projects[m.selectedProject].Urls = append(m.projects[m.selectedProject].Urls, namedURL{Name: m.urlNameBuffer, URL: m.inputBuffer})
-				cmd := m.updateProjectListItems()
-				m.saveProjects()
-				m.currentView = UrlListView
-				m.cursor = len(m.projects[m.selectedProject].Urls) - 1
-				m.urlNameBuffer = ""
-				m.inputBuffer = ""
-				m.focusedField = 0
-				return m, cmd
-			}
+		// Move on to whichever required field is still empty
+		if m.urlFields[urlNameField] == "" {
+			m.focusedField = urlNameField
+			return m, nil
 		}
-	case "backspace":
-		if m.focusedField == 0 {
-			m.urlNameBuffer = deleteLastRune(m.urlNameBuffer)
-		} else {
-			m.inputBuffer = deleteLastRune(m.inputBuffer)
+		if m.urlFields[urlAddressField] == "" {
+			m.focusedField = urlAddressField
+			return m, nil
+		}
+		newURL := namedURL{
+			Name:        m.urlFields[urlNameField],
+			URL:         m.urlFields[urlAddressField],
+			Description: m.urlFields[urlDescriptionField],
+			Environment: strings.TrimSpace(m.urlFields[urlEnvironmentField]),
+			Tags:        parseTags(m.urlFields[urlTagsField]),
+			Created:     time.Now(),
 		}
+		m.projects[m.selectedProject].Urls = append(m.projects[m.selectedProject].Urls, newURL)
+		m.currentView = UrlListView
+		m.cursor = len(m.projects[m.selectedProject].Urls) - 1
+		m.urlFields = [urlFieldCount]string{}
+		m.focusedField = 0
+		m.saveProjects()
+		return m, m.updateProjectListItems()
+	case "backspace":
+		m.urlFields[m.focusedField] = deleteLastRune(m.urlFields[m.focusedField])
 	case "tab":
-		m.focusedField = (m.focusedField + 1) % 2
+		m.focusedField = (m.focusedField + 1) % urlFieldCount
+	case "shift+tab":
+		m.focusedField = (m.focusedField + urlFieldCount - 1) % urlFieldCount
 	case " ":
-		if m.focusedField == 0 {
-			m.urlNameBuffer += " "
-		} else {
-			m.inputBuffer += " "
-		}
+		m.urlFields[m.focusedField] += " "
 	default:
 		if msg.Type == tea.KeyRunes {
-			if m.focusedField == 0 {
-				m.urlNameBuffer += string(msg.Runes)
-			} else {
-				m.inputBuffer += string(msg.Runes)
-			}
+			m.urlFields[m.focusedField] += string(msg.Runes)
 		}
 	}
 	return m, nil
@@ -416,11 +719,10 @@ func (m *model) updateConfirmDeleteProject(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		if m.selectedProject >= 0 && m.selectedProject < len(m.projects) {
 			deletedProjectName := m.projects[m.selectedProject].Name
 			m.projects = append(m.projects[:m.selectedProject], m.projects[m.selectedProject+1:]...)
-			cmd := m.updateProjectListItems()
-			m.saveProjects()
 			m.message = fmt.Sprintf("Deleted project '%s'", deletedProjectName)
 			m.currentView = ProjectListView
-			return m, cmd
+			m.saveProjects()
+			return m, m.updateProjectListItems()
 		}
 		m.currentView = ProjectListView
 	case "n", "esc":
@@ -429,9 +731,386 @@ func (m *model) updateConfirmDeleteProject(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 	return m, nil
 }
 
+func (m *model) updateRecovery(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		// The last option is always "start fresh"
+		if m.cursor < len(m.backups) {
+			m.cursor++
+		}
+	case "enter":
+		if m.cursor < len(m.backups) {
+			backup := m.backups[m.cursor]
+			m.projects = m.overlay.apply(backup.projects)
+			m.saveProjects()
+			if m.message == "" {
+				m.message = fmt.Sprintf("Restored %s from %s", pluralize(len(m.projects), "project", "projects"), backup.path)
+			}
+		} else {
+			m.projects = m.overlay.apply([]Project{})
+			m.message = "Started with an empty library"
+		}
+		m.recoveryNote = ""
+		m.backups = nil
+		m.currentView = ProjectListView
+		m.cursor = 0
+		return m, m.updateProjectListItems()
+	}
+	return m, nil
+}
+
+func (m *model) updateLibraryList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		m.currentView = ProjectListView
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.libraries)-1 {
+			m.cursor++
+		}
+	case "enter":
+		return m, m.switchLibrary(m.libraries[m.cursor])
+	}
+	return m, nil
+}
+
+func (m *model) updateColorFormat(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		m.currentView = m.previousView
+	case "up", "k":
+		if m.formatCursor > 0 {
+			m.formatCursor--
+		}
+	case "down", "j":
+		if m.formatCursor < len(colorFormats)-1 {
+			m.formatCursor++
+		}
+	case "enter":
+		m.copyColor(m.pickerColor, colorFormats[m.formatCursor])
+		m.currentView = m.previousView
+	case "s":
+		format := colorFormats[m.formatCursor]
+		updated := m.settings
+		updated.CopyFormat = format
+		if err := writeSettings(updated); err != nil {
+			m.message = fmt.Sprintf("Error saving settings: %v", err)
+		} else {
+			m.settings = updated
+			m.message = fmt.Sprintf("Colors are now copied as %s by default", format.label())
+		}
+	}
+	return m, nil
+}
+
+func (m *model) updateContrast(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	colors := m.projects[m.selectedProject].Colors
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		m.currentView = ColorListView
+	case "up", "k":
+		if m.contrastBg > 0 {
+			m.contrastBg--
+		}
+	case "down", "j":
+		if m.contrastBg < len(colors)-1 {
+			m.contrastBg++
+		}
+	case "x":
+		m.contrastText, m.contrastBg = m.contrastBg, m.contrastText
+	case "m":
+		m.contrastMatrix = !m.contrastMatrix
+	}
+	return m, nil
+}
+
+func (m *model) updateGeneratePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		m.currentView = ColorListView
+	case "up", "k":
+		if m.paletteCursor > 0 {
+			m.paletteCursor--
+		}
+	case "down", "j":
+		if m.paletteCursor < len(m.palette)-1 {
+			m.paletteCursor++
+		}
+	case " ":
+		m.paletteSelected[m.paletteCursor] = !m.paletteSelected[m.paletteCursor]
+	case "a":
+		// Select everything, or nothing if everything already is
+		all := true
+		for i := range m.palette {
+			all = all && m.paletteSelected[i]
+		}
+		for i := range m.palette {
+			m.paletteSelected[i] = !all
+		}
+	case "enter":
+		project := &m.projects[m.selectedProject]
+		added := 0
+		for i, entry := range m.palette {
+			if !m.paletteSelected[i] || containsColor(project.Colors, entry.color) {
+				continue
+			}
+			project.Colors = append(project.Colors, entry.color)
+			added++
+		}
+		if added == 0 {
+			m.message = "No new colors selected"
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Added %s", pluralize(added, "color", "colors"))
+		m.currentView = ColorListView
+		m.cursor = len(project.Colors) - 1
+		m.saveProjects()
+		return m, m.updateProjectListItems()
+	}
+	return m, nil
+}
+
+func (m *model) updateExport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		m.currentView = ColorListView
+	case "up", "k":
+		if m.exportCursor > 0 {
+			m.exportCursor--
+		}
+	case "down", "j":
+		if m.exportCursor < len(exportFormats)-1 {
+			m.exportCursor++
+		}
+	case "enter":
+		format := exportFormats[m.exportCursor]
+		m.currentView = ColorListView
+		files, err := format.write(m.projects[m.selectedProject])
+		if err != nil {
+			m.message = fmt.Sprintf("Error exporting: %v", err)
+			return m, nil
+		}
+		dir, err := os.Getwd()
+		if err != nil {
+			m.message = fmt.Sprintf("Error exporting: %v", err)
+			return m, nil
+		}
+		written, err := writeExport(dir, files)
+		if err != nil {
+			m.message = fmt.Sprintf("Error exporting: %v", err)
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Exported %s to %s", format.label, strings.Join(written, ", "))
+		if invalid := invalidColors(m.projects[m.selectedProject]); len(invalid) > 0 {
+			m.message += fmt.Sprintf(", skipped %s: %s", pluralize(len(invalid), "invalid color", "invalid colors"), strings.Join(invalid, ", "))
+		}
+	}
+	return m, nil
+}
+
+func (m *model) updateImport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.currentView = ColorListView
+		m.inputBuffer = ""
+		m.inputError = ""
+	case "enter":
+		if m.inputBuffer == "" {
+			return m, nil
+		}
+		path := expandHome(m.inputBuffer)
+		colors, err := importColors(path)
+		if err != nil {
+			m.inputError = err.Error()
+			return m, nil
+		}
+		m.importPath = path
+		m.imported, m.importSkipped = dedupeColors(m.projects[m.selectedProject].Colors, colors)
+		m.importSelected = map[int]bool{}
+		for i := range m.imported {
+			m.importSelected[i] = true
+		}
+		m.cursor = 0
+		m.inputBuffer = ""
+		m.inputError = ""
+		m.currentView = ImportPreviewView
+	case "backspace":
+		m.inputBuffer = deleteLastRune(m.inputBuffer)
+		m.inputError = ""
+	case " ":
+		m.inputBuffer += " "
+		m.inputError = ""
+	default:
+		if msg.Type == tea.KeyRunes {
+			m.inputBuffer += string(msg.Runes)
+			m.inputError = ""
+		}
+	}
+	return m, nil
+}
+
+func (m *model) updateImportPreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "n":
+		m.imported = nil
+		m.currentView = ColorListView
+		m.message = "Import cancelled"
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.imported)-1 {
+			m.cursor++
+		}
+	case " ":
+		m.importSelected[m.cursor] = !m.importSelected[m.cursor]
+	case "a":
+		// Select everything, or nothing if everything already is
+		all := true
+		for i := range m.imported {
+			all = all && m.importSelected[i]
+		}
+		for i := range m.imported {
+			m.importSelected[i] = !all
+		}
+	case "enter", "y":
+		var selected []namedColor
+		for i, c := range m.imported {
+			if m.importSelected[i] {
+				selected = append(selected, c)
+			}
+		}
+		if len(selected) == 0 {
+			m.imported = nil
+			m.currentView = ColorListView
+			m.message = "No colors imported"
+			return m, nil
+		}
+		project := &m.projects[m.selectedProject]
+		project.Colors = append(project.Colors, selected...)
+		m.message = fmt.Sprintf("Imported %s from %s", pluralize(len(selected), "color", "colors"), filepath.Base(m.importPath))
+		m.imported = nil
+		m.currentView = ColorListView
+		m.cursor = len(project.Colors) - 1
+		m.saveProjects()
+		return m, m.updateProjectListItems()
+	}
+	return m, nil
+}
+
+func (m *model) updatePalettePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "p", "enter":
+		m.currentView = ColorListView
+	}
+	return m, nil
+}
+
+// markURLUsed records that urls of project were just copied or opened. This
+// is best effort: a failure is appended to the message of the action, which
+// did succeed.
+func (m *model) markURLUsed(project string, urls ...namedURL) {
+	names := make([]string, len(urls))
+	for i, u := range urls {
+		names[i] = u.Name
+	}
+	path, err := getDataFilePath()
+	if err == nil {
+		var usage libraryUsage
+		if usage, err = recordURLUsage(path, project, names); err == nil {
+			m.urlUsage = usage
+		}
+	}
+	if err != nil {
+		m.message += fmt.Sprintf(" (could not record use: %v)", err)
+	}
+}
+
+// similarColors returns the stored colors near-identical to the one being
+// added, closest first.
+func (m *model) similarColors() []colorMatch {
+	c, err := parseColor(m.colorFields[colorValueField])
+	if err != nil {
+		return nil
+	}
+	return nearIdenticalColors(m.projects, c)
+}
+
+// openColorFormats shows the format picker for color, starting at the
+// default copy format.
+func (m *model) openColorFormats(color namedColor) {
+	m.pickerColor = color
+	m.previousView = m.currentView
+	m.formatCursor = 0
+	for i, f := range colorFormats {
+		if f == m.settings.copyFormat() {
+			m.formatCursor = i
+		}
+	}
+	m.currentView = ColorFormatView
+}
+
+// copyColor copies color to the clipboard in format f and reports the result
+// in m.message.
+func (m *model) copyColor(color namedColor, f colorFormat) {
+	value, err := formatColor(color.Value, f)
+	if err != nil {
+		m.message = fmt.Sprintf("Error formatting %s: %v", color.Value, err)
+		return
+	}
+	if err := clipboard.WriteAll(value); err != nil {
+		m.message = fmt.Sprintf("Error copying to clipboard: %v", err)
+		return
+	}
+	m.message = fmt.Sprintf(" Copied %s to clipboard! ", value)
+}
+
 // --- ENTRY POINT ---
 
 func main() {
+	args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diamonds: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(args) > 0 {
+		err := withDataLock(func() error {
+			return runCLI(args, os.Stdout)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "diamonds: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	m := initialModel()
 	p := tea.NewProgram(&m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// useTempDataFile points the data file and the config dir at a temporary
+// directory for the duration of t and returns the data file path.
+func useTempDataFile(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_DATA_HOME", "")
+
+	previous := dataFilePath
+	t.Cleanup(func() { dataFilePath = previous })
+	path := filepath.Join(dir, dataFileName)
+	if err := setDataFilePath(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeTestFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func keyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []string
+		message string
+	}{
+		{"restore a backup", []string{"enter"}, "Restored 1 project from"},
+		{"start fresh", []string{"down", "enter"}, "Started with an empty library"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := useTempDataFile(t)
+			writeTestFile(t, path, "{not json")
+			writeTestFile(t, path+backupSuffix, `{"schemaVersion":4,"projects":[{"name":"web","colors":[],"urls":[]}]}`)
+
+			m := initialModel()
+			if m.currentView != RecoveryView {
+				t.Fatalf("currentView = %v, want RecoveryView", m.currentView)
+			}
+			for _, key := range tt.keys {
+				m.Update(keyMsg(key))
+			}
+			if !strings.HasPrefix(m.message, tt.message) {
+				t.Errorf("message = %q, want it to start with %q", m.message, tt.message)
+			}
+
+			// The watcher must not report the recovery itself as an external change
+			stamp, _, err := readDataStamp(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !stamp.sameAs(m.dataStamp) {
+				t.Errorf("dataStamp does not match the data file after recovery")
+			}
+		})
+	}
+}
+
+func TestSwitchLibraryLoadsURLUsage(t *testing.T) {
+	path := useTempDataFile(t)
+	other := filepath.Join(filepath.Dir(path), "other.json")
+	for _, p := range []string{path, other} {
+		writeTestFile(t, p, `{"schemaVersion":6,"projects":[{"name":"web","colors":[],"urls":[{"name":"Docs","url":"https://example.com"}]}]}`)
+	}
+	if _, err := recordURLUsage(path, "web", []string{"Docs"}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initialModel()
+	if m.urlUsage["web"]["Docs"].IsZero() {
+		t.Fatal("usage of the default library was not loaded")
+	}
+	m.switchLibrary(library{Name: "other", Path: other})
+	if used := m.urlUsage["web"]["Docs"]; !used.IsZero() {
+		t.Errorf("other library shows Docs as used at %v", used)
+	}
+	m.switchLibrary(library{Name: defaultLibraryName, Path: path})
+	if m.urlUsage["web"]["Docs"].IsZero() {
+		t.Error("usage of the default library was lost after switching back")
+	}
+}
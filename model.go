@@ -1,11 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -13,18 +17,50 @@ import (
 
 const dataFileName = "data.json"
 const configDirName = "diamonds"
+const dataEnvVar = "DIAMONDS_DATA"
+const backupSuffix = ".bak"
+const maxBackups = 10
+const corruptSuffix = ".corrupt-"
+
+// dataFilePath overrides the default data file location. It is set from
+// --data or DIAMONDS_DATA at startup, and when switching libraries.
+var dataFilePath string
+
+// errCorruptData is wrapped by loadProjects when the data file exists but
+// cannot be parsed.
+var errCorruptData = errors.New("data file is corrupt")
 
 // --- DATA STRUCTURES ---
 
 type namedURL struct {
-	Name string `json:"name"`
-	URL  string `json:"url"`
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Environment string    `json:"environment,omitempty"` // e.g. prod, staging or dev
+	Created     time.Time `json:"created,omitzero"`
+}
+
+type namedColor struct {
+	Name  string   `json:"name,omitempty"`
+	Value string   `json:"value"`
+	Group string   `json:"group,omitempty"` // Role or group, e.g. "Primary"
+	Notes string   `json:"notes,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// label returns the name of the color, falling back to its value.
+func (c namedColor) label() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Value
 }
 
 type Project struct {
-	Name   string     `json:"name"`
-	Colors []string   `json:"colors"`
-	Urls   []namedURL `json:"urls"`
+	Name   string       `json:"name"`
+	Colors []namedColor `json:"colors"`
+	Urls   []namedURL   `json:"urls"`
 }
 
 // --- LIST ADAPTER (Project) ---
@@ -38,7 +74,7 @@ func (p *projectItem) FilterValue() string {
 	var b strings.Builder
 	b.WriteString(p.project.Name)
 	for _, c := range p.project.Colors {
-		b.WriteString(" " + c)
+		b.WriteString(" " + c.Value + " " + c.Name)
 	}
 	for _, u := range p.project.Urls {
 		b.WriteString(" " + u.Name)
@@ -48,42 +84,99 @@ func (p *projectItem) FilterValue() string {
 
 func (p *projectItem) Title() string { return p.project.Name }
 func (p *projectItem) Description() string {
-	colorCount := len(p.project.Colors)
-	urlCount := len(p.project.Urls)
-	colorStr := "colors"
-	if colorCount == 1 {
-		colorStr = "color"
-	}
-	urlStr := "URLs"
-	if urlCount == 1 {
-		urlStr = "URL"
+	return pluralize(len(p.project.Colors), "color", "colors") + ", " + pluralize(len(p.project.Urls), "URL", "URLs")
+}
+
+// pluralize formats a count followed by the matching singular or plural noun.
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
 	}
-	return fmt.Sprintf("%d %s, %d %s", colorCount, colorStr, urlCount, urlStr)
+	return fmt.Sprintf("%d %s", n, plural)
 }
 
 // --- LIST ADAPTER (Color & URL) ---
 
 type colorItem struct {
-	color   string
+	color   namedColor
 	project string
 }
 
-func (c *colorItem) FilterValue() string { return c.color + " " + c.project }
-func (c *colorItem) Title() string       { return c.color }
-func (c *colorItem) Description() string { return fmt.Sprintf("Color in %s", c.project) }
+func (c *colorItem) FilterValue() string {
+	fields := []string{c.color.Value, c.color.Name, c.color.Group, c.color.Notes, c.project}
+	return strings.Join(append(fields, c.color.Tags...), " ")
+}
+
+func (c *colorItem) Title() string { return c.color.label() }
+func (c *colorItem) Description() string {
+	desc := fmt.Sprintf("Color in %s", c.project)
+	if c.color.Name != "" {
+		desc = c.color.Value + " • " + c.project
+	}
+	if c.color.Group != "" {
+		desc += " • " + c.color.Group
+	}
+	return desc
+}
 
 type urlItem struct {
 	url     namedURL
 	project string
 }
 
-func (u *urlItem) FilterValue() string { return u.url.Name + " " + u.url.URL + " " + u.project }
-func (u *urlItem) Title() string       { return u.url.Name }
-func (u *urlItem) Description() string { return fmt.Sprintf("%s • %s", u.url.URL, u.project) }
+func (u *urlItem) FilterValue() string {
+	fields := []string{u.url.Name, u.url.URL, u.url.Description, u.url.Environment, u.project}
+	return strings.Join(append(fields, u.url.Tags...), " ")
+}
+
+func (u *urlItem) Title() string { return u.url.Name }
+func (u *urlItem) Description() string {
+	desc := fmt.Sprintf("%s • %s", u.url.URL, u.project)
+	if u.url.Environment != "" {
+		desc += " • " + u.url.Environment
+	}
+	return desc
+}
 
 // --- FILE I/O ---
 
 func getDataFilePath() (string, error) {
+	path := dataFilePath
+	if path == "" {
+		var err error
+		if path, err = defaultDataFilePath(); err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("could not create data dir: %w", err)
+	}
+	return path, nil
+}
+
+// defaultDataFilePath returns $XDG_DATA_HOME/diamonds/data.json when
+// XDG_DATA_HOME is set, unless the user already has a library in the
+// original config dir location and none in the XDG one.
+func defaultDataFilePath() (string, error) {
+	configDir, err := getAppConfigDir()
+	if err != nil {
+		return "", err
+	}
+	legacyPath := filepath.Join(configDir, dataFileName)
+
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		xdgPath := filepath.Join(dataHome, configDirName, dataFileName)
+		_, xdgErr := os.Stat(xdgPath)
+		_, legacyErr := os.Stat(legacyPath)
+		if xdgErr == nil || os.IsNotExist(legacyErr) {
+			return xdgPath, nil
+		}
+	}
+	return legacyPath, nil
+}
+
+func getAppConfigDir() (string, error) {
 	configDir, err := os.UserConfigDir()
 	if err != nil {
 		return "", fmt.Errorf("could not get user config dir: %w", err)
@@ -94,50 +187,594 @@ func getDataFilePath() (string, error) {
 		return "", fmt.Errorf("could not create app config dir: %w", err)
 	}
 
-	return filepath.Join(appConfigDir, dataFileName), nil
+	return appConfigDir, nil
+}
+
+// setDataFilePath makes path, when not empty, the data file used from now on.
+func setDataFilePath(path string) error {
+	if path == "" {
+		dataFilePath = ""
+		return nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("invalid data file path: %w", err)
+	}
+	dataFilePath = abs
+	return nil
 }
 
 func loadProjects() ([]Project, error) {
+	projects, _, err := loadProjectsStamped()
+	return projects, err
+}
+
+// loadProjectsStamped loads the projects together with the stamp of the file
+// they were read from.
+func loadProjectsStamped() ([]Project, dataStamp, error) {
 	path, err := getDataFilePath()
 	if err != nil {
-		return nil, fmt.Errorf("could not get data file path: %w", err)
+		return nil, dataStamp{}, fmt.Errorf("could not get data file path: %w", err)
 	}
+	return loadProjectsFrom(path)
+}
 
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return []Project{}, nil // No file, start fresh
+func loadProjectsFrom(path string) ([]Project, dataStamp, error) {
+	stamp, data, err := readDataStamp(path)
+	if err != nil {
+		return nil, dataStamp{}, err
 	}
+	if !stamp.exists {
+		return []Project{}, stamp, nil // No file, start fresh
+	}
+
+	projects, err := decodeDataFile(data)
+	return projects, stamp, err
+}
 
+func readProjectsFile(path string) ([]Project, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("could not read data file: %w", err)
 	}
 
-	var projects []Project
-	if err := json.Unmarshal(data, &projects); err != nil {
-		return nil, fmt.Errorf("could not parse data file: %w", err)
+	return decodeDataFile(data)
+}
+
+// --- SCHEMA ---
+
+// currentSchemaVersion is the version written by writeProjects. Bump it and
+// register a migration whenever the on-disk shape of the data changes.
+const currentSchemaVersion = 6
+
+// dataFile is the top-level envelope of data.json.
+type dataFile struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	Projects      []Project `json:"projects"`
+}
+
+// migration upgrades the raw JSON of a data file by exactly one schema version.
+type migration func(data []byte) ([]byte, error)
+
+// migrations maps a schema version to the migration that upgrades it to the
+// next one.
+var migrations = map[int]migration{
+	1: migrateV1ToV2,
+	2: migrateV2ToV3,
+	3: migrateV3ToV4,
+	4: migrateV4ToV5,
+	5: migrateV5ToV6,
+}
+
+// migrateV1ToV2 wraps the legacy bare []Project array in a dataFile envelope.
+func migrateV1ToV2(data []byte) ([]byte, error) {
+	return json.Marshal(struct {
+		SchemaVersion int             `json:"schemaVersion"`
+		Projects      json.RawMessage `json:"projects"`
+	}{2, data})
+}
+
+// migrateV2ToV3 turns the bare hex strings in Project.Colors into color
+// objects.
+func migrateV2ToV3(data []byte) ([]byte, error) {
+	var file struct {
+		Projects []map[string]json.RawMessage `json:"projects"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	for _, project := range file.Projects {
+		if err := upgradeLegacyColors(project); err != nil {
+			return nil, err
+		}
 	}
+	return json.Marshal(struct {
+		SchemaVersion int                          `json:"schemaVersion"`
+		Projects      []map[string]json.RawMessage `json:"projects"`
+	}{3, file.Projects})
+}
 
-	return projects, nil
+// migrateV3ToV4 only bumps the version. URLs gained optional metadata, which
+// older entries simply lack, but older versions of diamonds would drop it.
+func migrateV3ToV4(data []byte) ([]byte, error) {
+	var file struct {
+		Projects json.RawMessage `json:"projects"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		SchemaVersion int             `json:"schemaVersion"`
+		Projects      json.RawMessage `json:"projects"`
+	}{4, file.Projects})
 }
 
-// --- MODEL METHODS (Data) ---
+// migrateV4ToV5 stores every color value in its canonical form. Older
+// versions stored whatever was typed, e.g. "#fff". Values that cannot be
+// parsed, such as "#GGGGGG", are kept as they are so no data is lost, and are
+// flagged in the color list.
+func migrateV4ToV5(data []byte) ([]byte, error) {
+	var file struct {
+		Projects []map[string]json.RawMessage `json:"projects"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	for _, project := range file.Projects {
+		if err := canonicalizeColors(project); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(struct {
+		SchemaVersion int                          `json:"schemaVersion"`
+		Projects      []map[string]json.RawMessage `json:"projects"`
+	}{5, file.Projects})
+}
 
-func (m *model) saveProjects() {
+// migrateV5ToV6 drops URL "lastUsed" times, which moved to the usage file so
+// that using a URL no longer rewrites the library.
+func migrateV5ToV6(data []byte) ([]byte, error) {
+	var file struct {
+		Projects []map[string]json.RawMessage `json:"projects"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	for _, project := range file.Projects {
+		raw, ok := project["urls"]
+		if !ok || string(raw) == "null" {
+			continue
+		}
+		var urls []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &urls); err != nil {
+			return nil, err
+		}
+		for _, u := range urls {
+			delete(u, "lastUsed")
+		}
+		var err error
+		if project["urls"], err = json.Marshal(urls); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(struct {
+		SchemaVersion int                          `json:"schemaVersion"`
+		Projects      []map[string]json.RawMessage `json:"projects"`
+	}{6, file.Projects})
+}
+
+// upgradeLegacyColors rewrites the "colors" of a raw project from hex strings
+// to {"value": hex} objects.
+func upgradeLegacyColors(project map[string]json.RawMessage) error {
+	raw, ok := project["colors"]
+	if !ok || string(raw) == "null" {
+		return nil
+	}
+
+	var colors []string
+	if err := json.Unmarshal(raw, &colors); err != nil {
+		return err
+	}
+	upgraded := make([]map[string]string, len(colors))
+	for i, c := range colors {
+		upgraded[i] = map[string]string{"value": c}
+	}
+
+	var err error
+	project["colors"], err = json.Marshal(upgraded)
+	return err
+}
+
+// canonicalizeColors rewrites the value of every color of a raw project that
+// can be parsed to its canonical form, keeping all other fields.
+func canonicalizeColors(project map[string]json.RawMessage) error {
+	raw, ok := project["colors"]
+	if !ok || string(raw) == "null" {
+		return nil
+	}
+
+	var colors []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &colors); err != nil {
+		return err
+	}
+	for _, c := range colors {
+		var value string
+		if _, ok := c["value"]; !ok {
+			continue
+		}
+		if err := json.Unmarshal(c["value"], &value); err != nil {
+			return fmt.Errorf("invalid color value: %w", err)
+		}
+		if hex, err := normalizeColor(value); err == nil {
+			c["value"], _ = json.Marshal(hex)
+		}
+	}
+
+	var err error
+	project["colors"], err = json.Marshal(colors)
+	return err
+}
+
+// schemaVersion detects the version of a data file. Files written before the
+// envelope existed are a bare JSON array and count as version 1.
+func schemaVersion(data []byte) (int, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return 1, nil
+	}
+
+	var header struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(trimmed, &header); err != nil {
+		return 0, err
+	}
+	if header.SchemaVersion < 1 {
+		return 0, errors.New("missing schemaVersion")
+	}
+	return header.SchemaVersion, nil
+}
+
+// migrateDataFile upgrades data to currentSchemaVersion one step at a time.
+func migrateDataFile(data []byte) ([]byte, error) {
+	version, err := schemaVersion(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorruptData, err)
+	}
+	if version > currentSchemaVersion {
+		return nil, fmt.Errorf("data file uses schema version %d, but this version of diamonds only supports up to %d; please upgrade", version, currentSchemaVersion)
+	}
+
+	for ; version < currentSchemaVersion; version++ {
+		migrate, ok := migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("no migration from schema version %d", version)
+		}
+		if data, err = migrate(data); err != nil {
+			return nil, fmt.Errorf("%w: migrating from schema version %d: %v", errCorruptData, version, err)
+		}
+	}
+	return data, nil
+}
+
+func decodeDataFile(data []byte) ([]Project, error) {
+	data, err := migrateDataFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var file dataFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorruptData, err)
+	}
+	if file.Projects == nil {
+		file.Projects = []Project{}
+	}
+
+	return file.Projects, nil
+}
+
+// --- RECOVERY ---
+
+// backupFile is a readable backup of the data file that can be restored from
+// the recovery screen.
+type backupFile struct {
+	path     string
+	modTime  time.Time
+	projects []Project
+}
+
+// recoverDataFile moves a corrupt data file aside to a timestamped name and
+// returns a note describing what happened together with every backup that
+// can still be read, newest first.
+func recoverDataFile(loadErr error) (string, []backupFile, error) {
 	path, err := getDataFilePath()
 	if err != nil {
-		m.message = fmt.Sprintf("Error getting data path: %v", err)
-		return
+		return "", nil, fmt.Errorf("could not get data file path: %w", err)
+	}
+
+	movedPath := path + corruptSuffix + time.Now().Format("20060102-150405")
+	if err := os.Rename(path, movedPath); err != nil {
+		return "", nil, fmt.Errorf("could not move corrupt data file aside: %w", err)
+	}
+
+	matches, err := filepath.Glob(path + backupSuffix + "*")
+	if err != nil {
+		return "", nil, fmt.Errorf("could not list backups: %w", err)
+	}
+
+	var backups []backupFile
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		projects, err := readProjectsFile(match)
+		if err != nil {
+			continue // Skip backups that are unreadable too
+		}
+		backups = append(backups, backupFile{path: match, modTime: info.ModTime(), projects: projects})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].modTime.Equal(backups[j].modTime) {
+			return backups[i].modTime.After(backups[j].modTime)
+		}
+		return backups[i].path > backups[j].path // Timestamped names sort by age
+	})
+
+	note := fmt.Sprintf("Your data file could not be read (%v). It has been moved to %s.", loadErr, movedPath)
+	return note, backups, nil
+}
+
+func writeProjects(projects []Project) error {
+	path, err := getDataFilePath()
+	if err != nil {
+		return fmt.Errorf("could not get data file path: %w", err)
+	}
+
+	data, err := json.MarshalIndent(dataFile{SchemaVersion: currentSchemaVersion, Projects: projects}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode data: %w", err)
+	}
+
+	// Keep the previous versions around so a bad write can be undone
+	previous, err := os.ReadFile(path)
+	if err == nil {
+		if err := backupDataFile(path, previous); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("could not read data file: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("could not write data file: %w", err)
+	}
+	return nil
+}
+
+// backupDataFile writes previous, the contents of the data file at path, to a
+// timestamped backup such as data.json.bak-20060102-150405.000000000, and
+// removes the oldest backups beyond maxBackups. Backups are as private as
+// the data file.
+func backupDataFile(path string, previous []byte) error {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	backupPath := path + backupSuffix + "-" + time.Now().Format("20060102-150405.000000000")
+	if err := writeFileAtomic(backupPath, previous, perm); err != nil {
+		return fmt.Errorf("could not back up data file: %w", err)
+	}
+
+	// The timestamps sort in the order the backups were written
+	backups, err := filepath.Glob(path + backupSuffix + "-*")
+	if err != nil {
+		return fmt.Errorf("could not list backups: %w", err)
+	}
+	sort.Strings(backups)
+	for _, old := range backups[:max(len(backups)-maxBackups, 0)] {
+		if err := os.Remove(old); err != nil {
+			return fmt.Errorf("could not remove old backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path, flushes it to
+// disk and renames it over path, so a crash never leaves a truncated file.
+// When path is a symlink, e.g. into a dotfiles repository, the file it points
+// to is replaced instead of the link. An existing file keeps its mode; perm
+// only applies to new files.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	path, err := resolveSymlinks(path)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+	defer os.Remove(tmpPath) // No-op once the rename succeeded
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
 	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// Persist the rename itself. Not every platform can sync a directory,
+	// so this is best effort.
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
 
-	data, err := json.MarshalIndent(m.projects, "", "  ")
+// resolveSymlinks follows path through any symlinks to the file they point
+// to, which unlike filepath.EvalSymlinks may not exist yet.
+func resolveSymlinks(path string) (string, error) {
+	for range 40 {
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) || (err == nil && info.Mode()&os.ModeSymlink == 0) {
+			return path, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = target
+	}
+	return "", fmt.Errorf("too many levels of symbolic links: %s", path)
+}
+
+// --- VALIDATION ---
+
+// parseTags splits a comma-separated list of tags, dropping empty ones.
+func parseTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// isValidURL reports whether both the name and the URL of u are set.
+func isValidURL(u namedURL) bool {
+	return u.Name != "" && u.URL != ""
+}
+
+// findProject returns the index of the first project called name, or -1.
+func findProject(projects []Project, name string) int {
+	for i, p := range projects {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// --- MODEL METHODS (Data) ---
+
+// saveProjects writes m.projects to disk. If another instance has written the
+// file since we last loaded or saved it, both sets of changes are merged
+// first, so callers should rebuild the list after saving.
+func (m *model) saveProjects() {
+	err := withDataLock(func() error {
+		path, err := getDataFilePath()
+		if err != nil {
+			return fmt.Errorf("could not get data file path: %w", err)
+		}
+		stamp, data, err := readDataStamp(path)
+		if err != nil {
+			return err
+		}
+		disk := []Project{}
+		if stamp.exists {
+			if disk, err = decodeDataFile(data); err != nil {
+				return err
+			}
+		}
+
+		if !stamp.sameAs(m.dataStamp) {
+			m.replaceProjects(mergeProjects(m.baseProjects, m.projects, m.overlay.apply(disk)))
+			m.message = "Data was changed by another diamonds instance, both sets of changes were merged"
+		}
+
+		// Entries from .diamonds.json belong to the repository, not the library
+		if err := writeProjects(m.overlay.strip(m.projects, disk)); err != nil {
+			return err
+		}
+		m.dataStamp, _, err = readDataStamp(path)
+		m.baseProjects = cloneProjects(m.projects)
+		return err
+	})
 	if err != nil {
 		m.message = fmt.Sprintf("Error saving data: %v", err)
-		return
+	}
+}
+
+// reloadProjects applies a data file that was changed outside of this instance
+// and rebuilds whichever items the list is currently showing.
+func (m *model) reloadProjects(msg dataReloadMsg) tea.Cmd {
+	// A tick scheduled before our own save can report that save as a change,
+	// and one scheduled before switching libraries can report the old library
+	path, err := getDataFilePath()
+	if err != nil || msg.path != path || m.currentView == RecoveryView || msg.stamp.sameAs(m.dataStamp) {
+		return nil
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		m.message = fmt.Sprintf("Error writing data: %v", err)
+	// Remember the stamp even on error, so a broken file is only reported once
+	m.dataStamp = msg.stamp
+	if msg.err != nil {
+		m.message = fmt.Sprintf("Error reloading data: %v", msg.err)
+		return nil
 	}
+
+	projects := m.overlay.apply(msg.projects)
+	m.replaceProjects(projects)
+	m.baseProjects = cloneProjects(projects)
+	m.message = "Reloaded data after an external change"
+
+	if m.projectList.FilterState() != list.Unfiltered {
+		return m.switchToSearchItems()
+	}
+	return m.updateProjectListItems()
+}
+
+// switchLibrary opens lib in place of the current library. On failure the
+// current library stays open.
+func (m *model) switchLibrary(lib library) tea.Cmd {
+	previousPath := dataFilePath
+	if err := setDataFilePath(lib.Path); err != nil {
+		m.message = fmt.Sprintf("Error opening library '%s': %v", lib.Name, err)
+		return nil
+	}
+
+	projects, stamp, err := loadProjectsStamped()
+	if err != nil {
+		dataFilePath = previousPath
+		m.message = fmt.Sprintf("Error opening library '%s': %v", lib.Name, err)
+		return nil
+	}
+
+	m.projects = m.overlay.apply(projects)
+	m.dataStamp = stamp
+	m.baseProjects = cloneProjects(m.projects)
+	m.urlUsage = libraryURLUsage(dataFilePath)
+	m.libraryName = lib.Name
+	m.selectedProject = 0
+	m.cursor = 0
+	m.currentView = ProjectListView
+	m.projectList.Title = libraryTitle(lib.Name)
+	m.message = fmt.Sprintf("Switched to library '%s'", lib.Name)
+	return m.updateProjectListItems()
 }
 
 func (m *model) updateProjectListItems() tea.Cmd {
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// assertJSON fails t unless got and want decode to the same JSON value.
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("invalid expected JSON %s: %v", want, err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		migrate migration
+		in      string
+		want    string
+	}{
+		{
+			name:    "v1 bare array to v2 envelope",
+			migrate: migrateV1ToV2,
+			in:      `[{"name":"web","colors":["#FF5F87"],"urls":[]}]`,
+			want:    `{"schemaVersion":2,"projects":[{"name":"web","colors":["#FF5F87"],"urls":[]}]}`,
+		},
+		{
+			name:    "v1 empty array to v2 envelope",
+			migrate: migrateV1ToV2,
+			in:      `[]`,
+			want:    `{"schemaVersion":2,"projects":[]}`,
+		},
+		{
+			name:    "v2 string colors to v3 objects",
+			migrate: migrateV2ToV3,
+			in:      `{"schemaVersion":2,"projects":[{"name":"web","colors":["#FF5F87","#00AFFF"],"urls":[{"name":"Docs","url":"https://example.com"}]}]}`,
+			want:    `{"schemaVersion":3,"projects":[{"name":"web","colors":[{"value":"#FF5F87"},{"value":"#00AFFF"}],"urls":[{"name":"Docs","url":"https://example.com"}]}]}`,
+		},
+		{
+			name:    "v2 null and missing colors to v3",
+			migrate: migrateV2ToV3,
+			in:      `{"schemaVersion":2,"projects":[{"name":"a","colors":null},{"name":"b"}]}`,
+			want:    `{"schemaVersion":3,"projects":[{"name":"a","colors":null},{"name":"b"}]}`,
+		},
+		{
+			name:    "v3 to v4 keeps projects",
+			migrate: migrateV3ToV4,
+			in:      `{"schemaVersion":3,"projects":[{"name":"web","colors":[{"value":"#FF5F87","name":"Pink"}],"urls":[{"name":"Docs","url":"https://example.com"}]}]}`,
+			want:    `{"schemaVersion":4,"projects":[{"name":"web","colors":[{"value":"#FF5F87","name":"Pink"}],"urls":[{"name":"Docs","url":"https://example.com"}]}]}`,
+		},
+		{
+			name:    "v4 to v5 canonicalizes colors",
+			migrate: migrateV4ToV5,
+			in:      `{"schemaVersion":4,"projects":[{"name":"web","colors":[{"value":"#fff","name":"White"},{"value":"rgb(255 95 135 / 50%)"},{"value":"rebeccapurple"}],"urls":[]}]}`,
+			want:    `{"schemaVersion":5,"projects":[{"name":"web","colors":[{"value":"#FFFFFF","name":"White"},{"value":"#FF5F8780"},{"value":"#663399"}],"urls":[]}]}`,
+		},
+		{
+			name:    "v4 to v5 keeps invalid colors",
+			migrate: migrateV4ToV5,
+			in:      `{"schemaVersion":4,"projects":[{"name":"web","colors":[{"value":"#GGGGGG","notes":"typo"}]},{"name":"empty","colors":null}]}`,
+			want:    `{"schemaVersion":5,"projects":[{"name":"web","colors":[{"value":"#GGGGGG","notes":"typo"}]},{"name":"empty","colors":null}]}`,
+		},
+		{
+			name:    "v5 to v6 drops lastUsed",
+			migrate: migrateV5ToV6,
+			in:      `{"schemaVersion":5,"projects":[{"name":"web","colors":[],"urls":[{"name":"Docs","url":"https://example.com","created":"2026-01-02T03:04:05Z","lastUsed":"2026-02-03T04:05:06Z"}]},{"name":"empty","urls":null}]}`,
+			want:    `{"schemaVersion":6,"projects":[{"name":"web","colors":[],"urls":[{"name":"Docs","url":"https://example.com","created":"2026-01-02T03:04:05Z"}]},{"name":"empty","urls":null}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.migrate([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("migration failed: %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestMigrationsReject(t *testing.T) {
+	tests := []struct {
+		name    string
+		migrate migration
+		in      string
+	}{
+		{"v2 colors that are not strings", migrateV2ToV3, `{"schemaVersion":2,"projects":[{"name":"web","colors":[1,2]}]}`},
+		{"v2 projects that are not an array", migrateV2ToV3, `{"schemaVersion":2,"projects":{}}`},
+		{"v3 truncated file", migrateV3ToV4, `{"schemaVersion":3,"projects":[`},
+		{"v4 color value that is not a string", migrateV4ToV5, `{"schemaVersion":4,"projects":[{"name":"web","colors":[{"value":1}]}]}`},
+		{"v5 urls that are not objects", migrateV5ToV6, `{"schemaVersion":5,"projects":[{"name":"web","urls":["https://example.com"]}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.migrate([]byte(tt.in)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestMigrationsCoverEveryVersion(t *testing.T) {
+	for version := 1; version < currentSchemaVersion; version++ {
+		if migrations[version] == nil {
+			t.Errorf("no migration from schema version %d", version)
+		}
+	}
+}
+
+func TestDecodeDataFile(t *testing.T) {
+	want := []Project{{
+		Name:   "web",
+		Colors: []namedColor{{Value: "#FF5F87"}},
+		Urls:   []namedURL{{Name: "Docs", URL: "https://example.com"}},
+	}}
+
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"v1 bare array", `[{"name":"web","colors":["#FF5F87"],"urls":[{"name":"Docs","url":"https://example.com"}]}]`},
+		{"v2 envelope", `{"schemaVersion":2,"projects":[{"name":"web","colors":["#FF5F87"],"urls":[{"name":"Docs","url":"https://example.com"}]}]}`},
+		{"v3 color objects", `{"schemaVersion":3,"projects":[{"name":"web","colors":[{"value":"#FF5F87"}],"urls":[{"name":"Docs","url":"https://example.com"}]}]}`},
+		{"v1 lowercase hex", `[{"name":"web","colors":["#ff5f87"],"urls":[{"name":"Docs","url":"https://example.com"}]}]`},
+		{"v4 url metadata", `{"schemaVersion":4,"projects":[{"name":"web","colors":[{"value":"#FF5F87"}],"urls":[{"name":"Docs","url":"https://example.com"}]}]}`},
+		{"v5 canonical colors", `{"schemaVersion":5,"projects":[{"name":"web","colors":[{"value":"#FF5F87"}],"urls":[{"name":"Docs","url":"https://example.com","lastUsed":"2026-02-03T04:05:06Z"}]}]}`},
+		{"v6 without lastUsed", `{"schemaVersion":6,"projects":[{"name":"web","colors":[{"value":"#FF5F87"}],"urls":[{"name":"Docs","url":"https://example.com"}]}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeDataFile([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("decode failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeDataFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		corrupt bool   // Whether the error should offer recovery
+		message string // Part of the error message
+	}{
+		{"newer schema version", `{"schemaVersion":999,"projects":[]}`, false, "please upgrade"},
+		{"missing schemaVersion", `{"projects":[]}`, true, "missing schemaVersion"},
+		{"zero schemaVersion", `{"schemaVersion":0,"projects":[]}`, true, "missing schemaVersion"},
+		{"not JSON", `not json`, true, ""},
+		{"broken migration", `{"schemaVersion":2,"projects":[{"name":"web","colors":[1]}]}`, true, "schema version 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeDataFile([]byte(tt.in))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if errors.Is(err, errCorruptData) != tt.corrupt {
+				t.Errorf("errors.Is(err, errCorruptData) = %v, want %v (%v)", !tt.corrupt, tt.corrupt, err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error %q does not mention %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestWriteProjectsBackups(t *testing.T) {
+	path := useTempDataFile(t)
+	var versions []string
+	for i := range maxBackups + 3 {
+		projects := []Project{{Name: fmt.Sprintf("version %d", i), Colors: []namedColor{}, Urls: []namedURL{}}}
+		if err := writeProjects(projects); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, string(data))
+		if i == 0 {
+			if err := os.Chmod(path, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	backups, err := filepath.Glob(path + backupSuffix + "-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != maxBackups {
+		t.Fatalf("got %d backups, want %d", len(backups), maxBackups)
+	}
+	sort.Strings(backups)
+
+	// The newest backups are the versions before the current one
+	want := versions[len(versions)-1-maxBackups : len(versions)-1]
+	for i, backup := range backups {
+		data, err := os.ReadFile(backup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want[i] {
+			t.Errorf("backup %d holds %s, want %s", i, data, want[i])
+		}
+		info, err := os.Stat(backup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("backup %d has mode %v, want that of the data file", i, info.Mode().Perm())
+		}
+	}
+}
+
+func TestRecoverDataFileOffersEveryBackup(t *testing.T) {
+	path := useTempDataFile(t)
+	for i := range 3 {
+		if err := writeProjects([]Project{{Name: fmt.Sprintf("version %d", i)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, path, "{not json")
+
+	_, backups, err := recoverDataFile(errCorruptData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, b := range backups {
+		names = append(names, b.projects[0].Name)
+	}
+	if want := []string{"version 1", "version 0"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got backups %v, want %v", names, want)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	read := func(path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	mode := func(path string) os.FileMode {
+		t.Helper()
+		info, err := os.Lstat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Mode()
+	}
+
+	t.Run("new file", func(t *testing.T) {
+		path := filepath.Join(dir, "new.json")
+		if err := writeFileAtomic(path, []byte("new"), 0640); err != nil {
+			t.Fatal(err)
+		}
+		if got := read(path); got != "new" {
+			t.Errorf("got %q", got)
+		}
+		if got := mode(path).Perm(); got != 0640 {
+			t.Errorf("mode = %v, want 0640", got)
+		}
+	})
+
+	t.Run("keeps the mode", func(t *testing.T) {
+		path := filepath.Join(dir, "private.json")
+		writeTestFile(t, path, "old")
+		if err := os.Chmod(path, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeFileAtomic(path, []byte("new"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := mode(path).Perm(); got != 0600 {
+			t.Errorf("mode = %v, want 0600", got)
+		}
+	})
+
+	t.Run("follows symlinks", func(t *testing.T) {
+		target := filepath.Join(dir, "dotfiles", "data.json")
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, target, "old")
+		link := filepath.Join(dir, "link.json")
+		if err := os.Symlink(filepath.Join("dotfiles", "data.json"), link); err != nil {
+			t.Skipf("cannot create symlinks: %v", err)
+		}
+
+		if err := writeFileAtomic(link, []byte("new"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if mode(link)&os.ModeSymlink == 0 {
+			t.Error("the symlink was replaced by a regular file")
+		}
+		if got := read(target); got != "new" {
+			t.Errorf("target = %q, want %q", got, "new")
+		}
+	})
+
+	t.Run("dangling symlink", func(t *testing.T) {
+		target := filepath.Join(dir, "missing", "data.json")
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			t.Fatal(err)
+		}
+		link := filepath.Join(dir, "dangling.json")
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("cannot create symlinks: %v", err)
+		}
+
+		if err := writeFileAtomic(link, []byte("new"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := read(target); got != "new" {
+			t.Errorf("target = %q, want %q", got, "new")
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openerTimeout is how long an opener may run before it is assumed to have
+// handed the URL over, e.g. when the setting names a browser directly.
+const openerTimeout = 2 * time.Second
+
+// openURL opens url with command, or the platform's opener when command is
+// empty. It is a variable so it can be replaced, e.g. to open URLs without a
+// browser.
+var openURL = runOpener
+
+// urlsOpenedMsg reports how many URLs were opened, and why the next one
+// could not be.
+type urlsOpenedMsg struct {
+	count int
+	err   error
+}
+
+// openURLs opens urls one after the other with the configured command.
+func openURLs(command string, urls []string) tea.Cmd {
+	return func() tea.Msg {
+		for i, u := range urls {
+			if err := openURL(command, u); err != nil {
+				return urlsOpenedMsg{count: i, err: err}
+			}
+		}
+		return urlsOpenedMsg{count: len(urls)}
+	}
+}
+
+// runOpener starts command with url as its last argument and reports it
+// failing within openerTimeout.
+func runOpener(command, url string) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		args = platformOpener()
+	}
+
+	cmd := exec.Command(args[0], append(args[1:], url)...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("could not run %s: %w", args[0], err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("%s failed: %w", args[0], err)
+		}
+	case <-time.After(openerTimeout):
+	}
+	return nil
+}
+
+// platformOpener returns the command that opens URLs in the default browser.
+func platformOpener() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"open"}
+	case "windows":
+		// Unlike "cmd /c start", this does not treat & in URLs as a separator
+		return []string{"rundll32", "url.dll,FileProtocolHandler"}
+	}
+	return []string{"xdg-open"}
+}
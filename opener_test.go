@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runCmd runs cmd and the commands of any batch it returns, and collects the
+// messages they produce.
+func runCmd(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for _, c := range batch {
+		msgs = append(msgs, runCmd(c)...)
+	}
+	return msgs
+}
+
+func TestOpenURLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		failOn  string // URL the opener fails on
+		opened  []string
+		count   int
+		message string
+	}{
+		{"open one", "o", "", []string{"https://a.example"}, 1, "Opened URL in the browser"},
+		{"open one failing", "o", "https://a.example", nil, 0, "Error opening URL: no browser"},
+		{"open all", "O", "", []string{"https://a.example", "https://b.example", "https://c.example"}, 3, "Opened 3 URLs in the browser"},
+		{"open all failing halfway", "O", "https://b.example", []string{"https://a.example"}, 1, "Opened 1 URL, then: no browser"},
+		{"open all failing first", "O", "https://a.example", nil, 0, "Error opening URL: no browser"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := useTempDataFile(t)
+			library := `{"schemaVersion":6,"projects":[{"name":"web","colors":[],"urls":[
+				{"name":"A","url":"https://a.example"},
+				{"name":"B","url":"https://b.example"},
+				{"name":"C","url":"https://c.example"}]}]}`
+			writeTestFile(t, path, library)
+
+			var opened []string
+			previous := openURL
+			t.Cleanup(func() { openURL = previous })
+			openURL = func(command, url string) error {
+				if command != "my-browser --new-tab" {
+					t.Errorf("opener command = %q, want the configured one", command)
+				}
+				if url == tt.failOn {
+					return errors.New("no browser")
+				}
+				opened = append(opened, url)
+				return nil
+			}
+
+			m := initialModel()
+			m.settings.Opener = "my-browser --new-tab"
+			m.currentView = UrlListView
+			m.selectedProject = findProject(m.projects, "web")
+			_, cmd := m.Update(keyMsg(tt.key))
+
+			var result *urlsOpenedMsg
+			for _, msg := range runCmd(cmd) {
+				if msg, ok := msg.(urlsOpenedMsg); ok {
+					result = &msg
+					m.Update(msg)
+				}
+			}
+			if result == nil {
+				t.Fatal("no urlsOpenedMsg")
+			}
+			if result.count != tt.count {
+				t.Errorf("count = %d, want %d", result.count, tt.count)
+			}
+			if (result.err != nil) != (tt.failOn != "") {
+				t.Errorf("err = %v", result.err)
+			}
+			if !slices.Equal(opened, tt.opened) {
+				t.Errorf("opened %v, want %v", opened, tt.opened)
+			}
+			if m.message != tt.message {
+				t.Errorf("message = %q, want %q", m.message, tt.message)
+			}
+
+			// Every URL the key applies to counts as used, without touching
+			// the library
+			marked := 1
+			if tt.key == "O" {
+				marked = 3
+			}
+			for i, u := range m.projects[m.selectedProject].Urls {
+				if used := libraryURLUsage(path)["web"][u.Name]; used.IsZero() == (i < marked) {
+					t.Errorf("URL %s: last used %v", u.Name, used)
+				}
+			}
+			if data, err := os.ReadFile(path); err != nil || string(data) != library {
+				t.Errorf("opening URLs changed the data file")
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// repoFileName is the per-repository project file discovered by walking up
+// from the working directory.
+const repoFileName = ".diamonds.json"
+
+// --- DATA STRUCTURES ---
+
+// repoOverlay is a project shipped in a repository's .diamonds.json. Its
+// colors and URLs are shown on top of the open library but never written
+// to it.
+type repoOverlay struct {
+	path    string
+	project Project
+}
+
+// --- DISCOVERY ---
+
+// findRepoOverlay walks up from dir looking for a .diamonds.json file. It
+// returns nil and no error when there is none.
+func findRepoOverlay(dir string) (*repoOverlay, error) {
+	for {
+		path := filepath.Join(dir, repoFileName)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return parseRepoOverlay(path, data)
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("could not read %s: %w", path, err)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+func parseRepoOverlay(path string, data []byte) (*repoOverlay, error) {
+	// Colors may be given as plain hex strings, as in older data files
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err == nil && upgradeLegacyColors(raw) == nil {
+		data, _ = json.Marshal(raw)
+	}
+
+	var project Project
+	if err := json.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	if project.Name == "" {
+		return nil, fmt.Errorf("%s has no project name", path)
+	}
+	for i, c := range project.Colors {
+		value, err := normalizeColor(c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s has an invalid color %q: %w", path, c.Value, err)
+		}
+		project.Colors[i].Value = value
+	}
+	for _, u := range project.Urls {
+		if !isValidURL(u) {
+			return nil, errors.New(path + " has a URL without a name or address")
+		}
+	}
+	return &repoOverlay{path: path, project: project}, nil
+}
+
+// --- OVERLAY ---
+
+// apply returns a copy of projects with the overlay's colors and URLs added
+// to the project of the same name, which is created if needed.
+func (o *repoOverlay) apply(projects []Project) []Project {
+	projects = cloneProjects(projects)
+	if o == nil {
+		return projects
+	}
+
+	i := findProject(projects, o.project.Name)
+	if i < 0 {
+		projects = append(projects, Project{Name: o.project.Name, Colors: []namedColor{}, Urls: []namedURL{}})
+		i = len(projects) - 1
+	}
+	p := &projects[i]
+	for _, c := range o.project.Colors {
+		if !containsColor(p.Colors, c) {
+			p.Colors = append(p.Colors, c)
+		}
+	}
+	for _, u := range o.project.Urls {
+		if !containsURL(p.Urls, u) {
+			p.Urls = append(p.Urls, u)
+		}
+	}
+	return projects
+}
+
+// strip returns a copy of projects without the entries apply added, i.e.
+// overlay entries that are not also stored in disk, the library as it is on
+// disk. The overlay project is dropped entirely if nothing of it remains
+// and the library does not have it.
+func (o *repoOverlay) strip(projects, disk []Project) []Project {
+	projects = cloneProjects(projects)
+	i := -1
+	if o != nil {
+		i = findProject(projects, o.project.Name)
+	}
+	if i < 0 {
+		return projects
+	}
+
+	var stored Project
+	if j := findProject(disk, o.project.Name); j >= 0 {
+		stored = disk[j]
+	}
+
+	p := &projects[i]
+	colors := []namedColor{}
+	for _, c := range p.Colors {
+		if !containsColor(o.project.Colors, c) || containsColor(stored.Colors, c) {
+			colors = append(colors, c)
+		}
+	}
+	urls := []namedURL{}
+	for _, u := range p.Urls {
+		if !containsURL(o.project.Urls, u) || containsURL(stored.Urls, u) {
+			urls = append(urls, u)
+		}
+	}
+	p.Colors, p.Urls = colors, urls
+
+	if stored.Name == "" && len(colors) == 0 && len(urls) == 0 {
+		projects = append(projects[:i], projects[i+1:]...)
+	}
+	return projects
+}
+
+// hasColor reports whether color in project comes from the overlay.
+func (o *repoOverlay) hasColor(project string, color namedColor) bool {
+	return o != nil && o.project.Name == project && containsColor(o.project.Colors, color)
+}
+
+// hasURL reports whether u in project comes from the overlay.
+func (o *repoOverlay) hasURL(project string, u namedURL) bool {
+	return o != nil && o.project.Name == project && containsURL(o.project.Urls, u)
+}
+
+func containsColor(colors []namedColor, color namedColor) bool {
+	for _, c := range colors {
+		if canonicalColor(c.Value) == canonicalColor(color.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsURL(urls []namedURL, u namedURL) bool {
+	for _, v := range urls {
+		if v.Name == u.Name && v.URL == u.URL {
+			return true
+		}
+	}
+	return false
+}
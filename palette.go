@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+)
+
+// --- DATA STRUCTURES ---
+
+// paletteEntry is a color generated from a base color, e.g. one step of its
+// ramp or its complement.
+type paletteEntry struct {
+	kind  string
+	color namedColor
+}
+
+// rampStep is one step of a tint/shade ramp. A positive mix blends the base
+// color towards white, a negative one towards black.
+type rampStep struct {
+	label string
+	mix   float64
+}
+
+// rampSteps follows the 50-900 scale of Tailwind and Material, with the base
+// color at 500.
+var rampSteps = []rampStep{
+	{"50", 0.9},
+	{"100", 0.8},
+	{"200", 0.6},
+	{"300", 0.4},
+	{"400", 0.2},
+	{"500", 0},
+	{"600", -0.2},
+	{"700", -0.4},
+	{"800", -0.6},
+	{"900", -0.8},
+}
+
+// harmony is a set of colors at fixed hue offsets from the base color.
+type harmony struct {
+	kind    string
+	offsets []float64
+}
+
+var harmonies = []harmony{
+	{"Complementary", []float64{180}},
+	{"Analogous", []float64{-30, 30}},
+	{"Triadic", []float64{120, 240}},
+	{"Split complementary", []float64{150, 210}},
+}
+
+// --- GENERATION ---
+
+// generatePalette derives a tint/shade ramp and the color harmonies of base.
+// Generated colors keep the alpha and group of base and are named after it.
+func generatePalette(base namedColor) ([]paletteEntry, error) {
+	c, err := parseColor(base.Value)
+	if err != nil {
+		return nil, err
+	}
+	name := base.label()
+
+	var entries []paletteEntry
+	for _, step := range rampSteps {
+		mixed := c
+		if step.mix > 0 {
+			mixed = mixColors(c, white, step.mix)
+		} else if step.mix < 0 {
+			mixed = mixColors(c, rgba{0, 0, 0, 255}, -step.mix)
+		}
+		entries = append(entries, paletteEntry{
+			kind:  "Ramp",
+			color: namedColor{Value: mixed.hex(), Name: name + " " + step.label, Group: base.Group},
+		})
+	}
+
+	r, g, b, _ := c.floats()
+	h, s, l := rgbToHSL(r, g, b)
+	for _, hm := range harmonies {
+		for i, offset := range hm.offsets {
+			r, g, b := hslToRGB(math.Mod(h+offset+360, 360), s, l)
+			rotated := rgba{to8Bit(r), to8Bit(g), to8Bit(b), c.a}
+			label := fmt.Sprintf("%s %s", name, strings.ToLower(hm.kind))
+			if len(hm.offsets) > 1 {
+				label += fmt.Sprintf(" %d", i+1)
+			}
+			entries = append(entries, paletteEntry{
+				kind:  hm.kind,
+				color: namedColor{Value: rotated.hex(), Name: label, Group: base.Group},
+			})
+		}
+	}
+	return entries, nil
+}
+
+// mixColors blends c towards other by amount in 0-1, keeping the alpha of c.
+func mixColors(c, other rgba, amount float64) rgba {
+	blend := func(a, b uint8) uint8 {
+		return to8Bit((float64(a)*(1-amount) + float64(b)*amount) / 255)
+	}
+	return rgba{blend(c.r, other.r), blend(c.g, other.g), blend(c.b, other.b), c.a}
+}
+
+// --- EXTRACTION ---
+
+// extractedColors is how many dominant colors are taken from an image.
+const extractedColors = 8
+
+// maxSamples caps how many pixels of an image are clustered.
+const maxSamples = 65536
+
+// colorBox is a set of pixels median cut keeps splitting.
+type colorBox []rgba
+
+// extractPalette returns up to n dominant colors of img, most common first,
+// using median cut. Mostly transparent pixels are ignored.
+func extractPalette(img image.Image, n int) []rgba {
+	bounds := img.Bounds()
+	step := 1
+	for (bounds.Dx()/step)*(bounds.Dy()/step) > maxSamples {
+		step++
+	}
+
+	var pixels colorBox
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 128 {
+				continue
+			}
+			pixels = append(pixels, rgba{c.R, c.G, c.B, 255})
+		}
+	}
+	if len(pixels) == 0 {
+		return nil
+	}
+
+	boxes := []colorBox{pixels}
+	for len(boxes) < n {
+		// Split the box spanning the widest range of a channel
+		widest, widestRange := -1, 0
+		for i, box := range boxes {
+			if _, r := box.longestChannel(); r > widestRange && len(box) > 1 {
+				widest, widestRange = i, r
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		low, high := boxes[widest].split()
+		boxes = append(boxes[:widest], append([]colorBox{low, high}, boxes[widest+1:]...)...)
+	}
+
+	sort.SliceStable(boxes, func(i, j int) bool { return len(boxes[i]) > len(boxes[j]) })
+	colors := make([]rgba, len(boxes))
+	for i, box := range boxes {
+		colors[i] = box.average()
+	}
+	return colors
+}
+
+// longestChannel returns the channel (0 red, 1 green, 2 blue) whose values
+// are spread the most in box, and that spread.
+func (box colorBox) longestChannel() (channel, spread int) {
+	for ch := 0; ch < 3; ch++ {
+		lo, hi := 255, 0
+		for _, c := range box {
+			v := int(c.channel(ch))
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		if hi-lo > spread {
+			channel, spread = ch, hi-lo
+		}
+	}
+	return channel, spread
+}
+
+// split sorts box along its longest channel and cuts it at the median,
+// moved to the nearest change of value so equal pixels stay together.
+func (box colorBox) split() (colorBox, colorBox) {
+	ch, _ := box.longestChannel()
+	sort.Slice(box, func(i, j int) bool { return box[i].channel(ch) < box[j].channel(ch) })
+
+	mid := len(box) / 2
+	v := box[mid].channel(ch)
+	lo, hi := mid, mid
+	for lo > 0 && box[lo-1].channel(ch) == v {
+		lo--
+	}
+	for hi < len(box) && box[hi].channel(ch) == v {
+		hi++
+	}
+	if lo > 0 && (mid-lo <= hi-mid || hi == len(box)) {
+		mid = lo
+	} else {
+		mid = hi
+	}
+	return box[:mid], box[mid:]
+}
+
+func (box colorBox) average() rgba {
+	var r, g, b int
+	for _, c := range box {
+		r, g, b = r+int(c.r), g+int(c.g), b+int(c.b)
+	}
+	n := len(box)
+	return rgba{uint8((r + n/2) / n), uint8((g + n/2) / n), uint8((b + n/2) / n), 255}
+}
+
+func (c rgba) channel(ch int) uint8 {
+	switch ch {
+	case 0:
+		return c.r
+	case 1:
+		return c.g
+	}
+	return c.b
+}
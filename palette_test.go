@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractPalette(t *testing.T) {
+	pink := color.NRGBA{0xFF, 0x5F, 0x87, 0xFF}
+	blue := color.NRGBA{0x00, 0x00, 0xFF, 0xFF}
+
+	// Three quarters pink, one quarter blue
+	twoColors := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for y := range 4 {
+		for x := range 4 {
+			twoColors.Set(x, y, pink)
+			if x == 3 {
+				twoColors.Set(x, y, blue)
+			}
+		}
+	}
+	want := []rgba{{0xFF, 0x5F, 0x87, 255}, {0x00, 0x00, 0xFF, 255}}
+	if got := extractPalette(twoColors, extractedColors); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	transparent := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	if got := extractPalette(transparent, extractedColors); got != nil {
+		t.Errorf("got %v for a transparent image, want nil", got)
+	}
+}
+
+func TestColorBoxSplit(t *testing.T) {
+	// The median falls among the 10s, which must all land in the same half
+	box := colorBox{{30, 0, 0, 255}, {10, 0, 0, 255}, {10, 0, 0, 255}, {10, 0, 0, 255}, {0, 0, 0, 255}}
+	low, high := box.split()
+
+	want := [2]colorBox{{{0, 0, 0, 255}}, {{10, 0, 0, 255}, {10, 0, 0, 255}, {10, 0, 0, 255}, {30, 0, 0, 255}}}
+	if got := [2]colorBox{low, high}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestColorBoxAverage(t *testing.T) {
+	box := colorBox{{0, 100, 255, 255}, {255, 101, 0, 255}}
+	if got, want := box.average(), (rgba{128, 101, 128, 255}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseImageColorsRejectsLargeImages(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+
+	// Claim 100000x100000 pixels in the IHDR chunk, which follows the
+	// 8 byte signature, and fix up its checksum
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:], 100000)
+	binary.BigEndian.PutUint32(data[20:], 100000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	_, err := parseImageColors(data)
+	if err == nil || !strings.Contains(err.Error(), "megapixel limit") {
+		t.Errorf("got error %v, want the megapixel limit", err)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const settingsFileName = "settings.json"
+
+// --- DATA STRUCTURES ---
+
+// settings holds per-user preferences. They live in the config dir rather
+// than in a library, so they follow the user across libraries.
+type settings struct {
+	CopyFormat colorFormat `json:"copyFormat,omitempty"`
+	Opener     string      `json:"opener,omitempty"` // Command URLs are opened with, e.g. "firefox --new-tab"
+}
+
+// copyFormat returns the format colors are copied in by default.
+func (s settings) copyFormat() colorFormat {
+	if s.CopyFormat == "" {
+		return formatHex
+	}
+	return s.CopyFormat
+}
+
+// --- FILE I/O ---
+
+func getSettingsFilePath() (string, error) {
+	configDir, err := getAppConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, settingsFileName), nil
+}
+
+func loadSettings() (settings, error) {
+	path, err := getSettingsFilePath()
+	if err != nil {
+		return settings{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings{}, nil
+	}
+	if err != nil {
+		return settings{}, fmt.Errorf("could not read settings file: %w", err)
+	}
+
+	var s settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return settings{}, fmt.Errorf("could not parse settings file: %w", err)
+	}
+	if s.CopyFormat != "" {
+		if _, err := parseColorFormat(string(s.CopyFormat)); err != nil {
+			return settings{}, fmt.Errorf("invalid settings file: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func writeSettings(s settings) error {
+	path, err := getSettingsFilePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode settings: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("could not write settings file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const lockSuffix = ".lock"
+
+// watchInterval is how often a running TUI checks the data file for edits
+// made outside of it.
+const watchInterval = time.Second
+
+// --- LOCKING ---
+
+// withDataLock runs fn while holding an exclusive advisory lock on the data
+// file, so read-modify-write cycles of concurrent instances do not interleave.
+// The lock lives in a separate file because writes replace data.json itself.
+func withDataLock(fn func() error) error {
+	path, err := getDataFilePath()
+	if err != nil {
+		return fmt.Errorf("could not get data file path: %w", err)
+	}
+
+	f, err := os.OpenFile(path+lockSuffix, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open lock file: %w", err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("could not lock data file: %w", err)
+	}
+	defer unlockFile(f)
+
+	return fn()
+}
+
+// --- CHANGE DETECTION ---
+
+// dataStamp identifies one version of the data file on disk.
+type dataStamp struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// readDataStamp stats and reads the file at path. A missing file yields a
+// zero stamp and no error.
+func readDataStamp(path string) (dataStamp, []byte, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return dataStamp{}, nil, nil
+	}
+	if err != nil {
+		return dataStamp{}, nil, fmt.Errorf("could not stat data file: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return dataStamp{}, nil, fmt.Errorf("could not read data file: %w", err)
+	}
+
+	return dataStamp{
+		exists:  true,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		hash:    sha256.Sum256(data),
+	}, data, nil
+}
+
+// sameAs reports whether both stamps describe the same file contents. A
+// different mtime alone (e.g. after a touch) does not count as a change.
+func (s dataStamp) sameAs(other dataStamp) bool {
+	if s.exists != other.exists {
+		return false
+	}
+	if s.modTime.Equal(other.modTime) && s.size == other.size {
+		return true
+	}
+	return s.hash == other.hash
+}
+
+// --- LIVE RELOAD ---
+
+// watchTickMsg is sent by watchDataFile when the data file has not changed.
+type watchTickMsg struct{}
+
+// dataReloadMsg is sent by watchDataFile when the data file has changed.
+type dataReloadMsg struct {
+	path     string
+	projects []Project
+	stamp    dataStamp
+	err      error
+}
+
+// watchDataFile waits for watchInterval and then checks whether the data file
+// still matches stamp. It has to be rescheduled after every message.
+func watchDataFile(stamp dataStamp) tea.Cmd {
+	// Resolve the path now, as the tick runs outside of the update loop
+	path, err := getDataFilePath()
+	return tea.Tick(watchInterval, func(time.Time) tea.Msg {
+		if err != nil {
+			return watchTickMsg{}
+		}
+		current, _, err := readDataStamp(path)
+		if err != nil || current.sameAs(stamp) {
+			return watchTickMsg{}
+		}
+
+		projects, current, err := loadProjectsFrom(path)
+		return dataReloadMsg{path: path, projects: projects, stamp: current, err: err}
+	})
+}
+
+// --- MERGING ---
+
+// mergeProjects applies our changes, relative to base, on top of theirs.
+// base is the version we last loaded or saved, ours is the in-memory state
+// and theirs is what another instance has since written. Projects are
+// matched by name, colors by value and URLs by name and address; when one
+// side deleted a project, the deletion wins.
+func mergeProjects(base, ours, theirs []Project) []Project {
+	merged := cloneProjects(theirs)
+
+	// Drop projects we deleted
+	for _, b := range base {
+		if findProject(ours, b.Name) >= 0 {
+			continue
+		}
+		if i := findProject(merged, b.Name); i >= 0 {
+			merged = append(merged[:i], merged[i+1:]...)
+		}
+	}
+
+	for _, o := range ours {
+		bi := findProject(base, o.Name)
+		mi := findProject(merged, o.Name)
+		switch {
+		case mi < 0 && bi < 0:
+			// We added it
+			merged = append(merged, cloneProjects([]Project{o})[0])
+		case mi < 0:
+			// They deleted it
+		default:
+			var b Project
+			if bi >= 0 {
+				b = base[bi]
+			}
+			merged[mi].Colors = mergeSlice(b.Colors, o.Colors, merged[mi].Colors, func(c namedColor) string { return canonicalColor(c.Value) })
+			merged[mi].Urls = mergeSlice(b.Urls, o.Urls, merged[mi].Urls, func(u namedURL) string { return u.Name + "\x00" + u.URL })
+		}
+	}
+	return merged
+}
+
+// mergeSlice removes from theirs the entries we deleted since base and
+// appends the entries we added, using key to identify entries.
+func mergeSlice[T any](base, ours, theirs []T, key func(T) string) []T {
+	inBase := make(map[string]bool, len(base))
+	for _, v := range base {
+		inBase[key(v)] = true
+	}
+	inOurs := make(map[string]bool, len(ours))
+	for _, v := range ours {
+		inOurs[key(v)] = true
+	}
+
+	result := make([]T, 0, len(theirs)+len(ours))
+	seen := make(map[string]bool, len(theirs))
+	for _, v := range theirs {
+		k := key(v)
+		if inBase[k] && !inOurs[k] {
+			continue
+		}
+		result = append(result, v)
+		seen[k] = true
+	}
+	for _, v := range ours {
+		k := key(v)
+		if !inBase[k] && !seen[k] {
+			result = append(result, v)
+			seen[k] = true
+		}
+	}
+	return result
+}
+
+// cloneProjects deep-copies projects, so edits made in place (e.g. deleting
+// a color) do not leak into the copy.
+func cloneProjects(projects []Project) []Project {
+	clone := make([]Project, len(projects))
+	for i, p := range projects {
+		clone[i] = Project{
+			Name:   p.Name,
+			Colors: cloneColors(p.Colors),
+			Urls:   cloneURLs(p.Urls),
+		}
+	}
+	return clone
+}
+
+func cloneColors(colors []namedColor) []namedColor {
+	clone := make([]namedColor, len(colors))
+	for i, c := range colors {
+		clone[i] = c
+		clone[i].Tags = append([]string(nil), c.Tags...)
+	}
+	return clone
+}
+
+func cloneURLs(urls []namedURL) []namedURL {
+	clone := make([]namedURL, len(urls))
+	for i, u := range urls {
+		clone[i] = u
+		clone[i].Tags = append([]string(nil), u.Tags...)
+	}
+	return clone
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const usageFileName = "usage.json"
+
+// --- DATA STRUCTURES ---
+
+// urlUsage records when URLs were last copied or opened, by data file. It
+// lives in the config dir rather than in the library, so using a URL never
+// rewrites a shared or read-only library.
+type urlUsage map[string]libraryUsage
+
+// libraryUsage maps project names to URL names to when they were last used.
+type libraryUsage map[string]map[string]time.Time
+
+// record sets when the URLs called names in project were last used.
+func (u libraryUsage) record(project string, names []string, t time.Time) {
+	if u[project] == nil {
+		u[project] = map[string]time.Time{}
+	}
+	for _, name := range names {
+		u[project][name] = t
+	}
+}
+
+// --- FILE I/O ---
+
+func getUsageFilePath() (string, error) {
+	configDir, err := getAppConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, usageFileName), nil
+}
+
+func loadURLUsage() (urlUsage, error) {
+	path, err := getUsageFilePath()
+	if err != nil {
+		return urlUsage{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return urlUsage{}, nil
+	}
+	if err != nil {
+		return urlUsage{}, fmt.Errorf("could not read usage file: %w", err)
+	}
+
+	usage := urlUsage{}
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return urlUsage{}, fmt.Errorf("could not parse usage file: %w", err)
+	}
+	return usage, nil
+}
+
+// libraryURLUsage returns the usage of the library at dataPath. Usage is
+// informational, so an unreadable usage file counts as empty.
+func libraryURLUsage(dataPath string) libraryUsage {
+	usage, _ := loadURLUsage()
+	return usage[dataPath]
+}
+
+// recordURLUsage marks the URLs called names in project of the library at
+// dataPath as used now. It rereads the usage file first, so uses recorded by
+// other instances are kept, and returns the usage of that library.
+func recordURLUsage(dataPath, project string, names []string) (libraryUsage, error) {
+	usage, err := loadURLUsage()
+	if err != nil {
+		return nil, err
+	}
+	if usage[dataPath] == nil {
+		usage[dataPath] = libraryUsage{}
+	}
+	usage[dataPath].record(project, names, time.Now())
+
+	path, err := getUsageFilePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("could not encode usage: %w", err)
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("could not write usage file: %w", err)
+	}
+	return usage[dataPath], nil
+}
@@ -2,10 +2,14 @@ package main
 
 import (
 	"fmt"
+	"math"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 // ViewState determines which view is currently active.
@@ -20,6 +24,15 @@ const (
 	AddUrlView
 	ProjectMenuView
 	ConfirmDeleteProjectView
+	RecoveryView
+	LibraryListView
+	ColorFormatView
+	ContrastView
+	GeneratePaletteView
+	ExportView
+	ImportView
+	ImportPreviewView
+	PalettePreviewView
 )
 
 // --- STYLING ---
@@ -37,6 +50,10 @@ var (
 	quoteColor        = lipgloss.AdaptiveColor{Light: "#1E90FF", Dark: "#FF59C8"}
 	normalTextColor   = lipgloss.AdaptiveColor{Light: "#1F2026", Dark: "#E5E5E5"}
 
+	// Checkerboard behind translucent swatches
+	checkerLight = rgba{0xCC, 0xCC, 0xCC, 255}
+	checkerDark  = rgba{0x66, 0x66, 0x66, 255}
+
 	// Styles
 	headerStyle = lipgloss.NewStyle().
 			Foreground(appNameColor).
@@ -71,6 +88,27 @@ var (
 			Width(40)
 
 	docStyle = lipgloss.NewStyle().Padding(2, 1).Foreground(normalTextColor)
+
+	warningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFAF00"))
+
+	environmentColors = map[string]lipgloss.Color{
+		"prod":        lipgloss.Color("#C62828"),
+		"production":  lipgloss.Color("#C62828"),
+		"staging":     lipgloss.Color("#EF6C00"),
+		"dev":         lipgloss.Color("#2E7D32"),
+		"development": lipgloss.Color("#2E7D32"),
+	}
+
+	passBadgeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#F1F1F1")).
+			Background(lipgloss.Color("#2E7D32")).
+			Padding(0, 1)
+
+	failBadgeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#F1F1F1")).
+			Background(lipgloss.Color("#C62828")).
+			Padding(0, 1)
 )
 
 func newCustomDelegate() list.DefaultDelegate {
@@ -104,6 +142,25 @@ func (m *model) View() string {
 		view = m.viewAddUrl()
 	case ConfirmDeleteProjectView:
 		view = m.viewConfirmDeleteProject()
+	case RecoveryView:
+		view = m.viewRecovery()
+	case LibraryListView:
+		view = m.viewLibraryList()
+	case ColorFormatView:
+		view = m.viewColorFormat()
+	case ContrastView:
+		view = m.viewContrast()
+	case GeneratePaletteView:
+		view = m.viewGeneratePalette()
+	case ExportView:
+		view = m.viewExport()
+	case ImportView:
+		view = m.viewImport()
+	case ImportPreviewView:
+		view = m.viewImportPreview()
+	case PalettePreviewView:
+		// Full screen, without the usual padding
+		return m.viewPalettePreview()
 	}
 	return docStyle.Render(view)
 }
@@ -111,7 +168,10 @@ func (m *model) View() string {
 func (m *model) viewProjectList() string {
 	var b strings.Builder
 	b.WriteString(m.projectList.View())
-	help := horizontalHelp("↑/↓ navigate", "/ search", "n new", "d delete", "q quit")
+	help := horizontalHelp("↑/↓ navigate", "/ search", "n new", "d delete", "L libraries", "q quit")
+	if m.projectList.FilterState() != list.Unfiltered {
+		help = horizontalHelp("↑/↓ navigate", "enter copy", "ctrl+f copy as", "ctrl+o open", "esc cancel")
+	}
 	b.WriteString("\n" + help)
 
 	if m.message != "" {
@@ -125,6 +185,9 @@ func (m *model) viewProjectMenu() string {
 	var b strings.Builder
 
 	b.WriteString(headerStyle.Render("✨ " + project.Name) + "\n")
+	if m.overlay != nil && m.overlay.project.Name == project.Name {
+		b.WriteString(subtleStyle.Render("Includes entries from "+m.overlay.path) + "\n\n")
+	}
 
 	options := []string{"Colors", "URLs"}
 	for i, option := range options {
@@ -145,28 +208,391 @@ func (m *model) viewColorList() string {
 	project := m.projects[m.selectedProject]
 	var b strings.Builder
 
-	b.WriteString(headerStyle.Render(project.Name) + "\n")
+	header := project.Name
+	if m.simulation != normalVision {
+		header += subtleStyle.Render(" · simulating " + m.simulation.String())
+	}
+	b.WriteString(headerStyle.Render(header) + "\n")
 
 	if len(project.Colors) == 0 {
 		b.WriteString(subtleStyle.Render("No colors yet. Press 'n' to add one.") + "\n")
 	} else {
+		confusable := confusableColors(project.Colors, m.simulation)
 		for i, color := range project.Colors {
-			colorBlock := lipgloss.NewStyle().Background(lipgloss.Color(color)).Render("  ")
-			hexCodeStyled := inlineCodeStyle.Render(color)
-			line := fmt.Sprintf("%s %s", colorBlock, hexCodeStyled)
+			swatch := colorSwatch(color.Value)
+			if c, err := parseColor(color.Value); err == nil && m.simulation != normalVision {
+				swatch = colorSwatch(m.simulation.simulate(c).hex())
+			}
+			hexCodeStyled := inlineCodeStyle.Render(color.Value)
+			line := fmt.Sprintf("%s %s", swatch, hexCodeStyled)
+			if color.Name != "" {
+				line += " " + color.Name
+			}
+			if color.Group != "" {
+				line += " " + subtleStyle.Render("· "+color.Group)
+			}
+			for _, tag := range color.Tags {
+				line += " " + subtleStyle.Render("#"+tag)
+			}
+			if len(confusable[i]) > 0 {
+				var labels []string
+				for _, j := range confusable[i] {
+					labels = append(labels, project.Colors[j].label())
+				}
+				line += " " + warningStyle.Render("⚠ looks like "+strings.Join(labels, ", "))
+			}
 
 			if m.cursor == i {
 				cursorStyle := lipgloss.NewStyle().Foreground(selectionColor)
 				styledCursor := cursorStyle.Render("> ")
 				styledLine := selectedItemStyle.Render(line)
 				b.WriteString(styledCursor + styledLine + "\n")
+				if color.Notes != "" {
+					b.WriteString("     " + subtleStyle.Render(color.Notes) + "\n")
+				}
 			} else {
 				b.WriteString("  " + line + "\n")
 			}
 		}
 	}
 
-	help := horizontalHelp("↑/↓ navigate", "enter copy", "n new", "d delete", "esc back", "q quit")
+	if note := colorProfileNote(); note != "" && len(project.Colors) > 0 {
+		b.WriteString("\n" + subtleStyle.Render(note) + "\n")
+	}
+
+	help := horizontalHelp("↑/↓ navigate", "enter copy", "f copy as", "c contrast", "v vision", "p preview", "g generate", "i import", "e export", "n new", "d delete", "esc back", "q quit")
+	b.WriteString("\n" + help)
+
+	if m.message != "" {
+		b.WriteString("\n" + messageStyle.Render(m.message))
+	}
+
+	return b.String()
+}
+
+// confusableColors returns, for every color, the indices of the other colors
+// that only become hard to tell apart with the deficiency v.
+func confusableColors(colors []namedColor, v visionDeficiency) [][]int {
+	confusable := make([][]int, len(colors))
+	if v == normalVision {
+		return confusable
+	}
+
+	parsed := make([]rgba, len(colors))
+	valid := make([]bool, len(colors))
+	for i, color := range colors {
+		c, err := parseColor(color.Value)
+		parsed[i], valid[i] = c, err == nil
+	}
+	for i := range colors {
+		for j := i + 1; j < len(colors); j++ {
+			if !valid[i] || !valid[j] || colorDistance(parsed[i], parsed[j]) < indistinguishableDistance {
+				continue
+			}
+			if colorDistance(v.simulate(parsed[i]), v.simulate(parsed[j])) < indistinguishableDistance {
+				confusable[i] = append(confusable[i], j)
+				confusable[j] = append(confusable[j], i)
+			}
+		}
+	}
+	return confusable
+}
+
+// colorSwatch renders a two-cell preview of value. Translucent colors are
+// composited over a checkerboard, drawn with half blocks so that each cell
+// holds two squares. Unless the terminal has true color, a third cell marks
+// swatches it can only approximate with ≈.
+func colorSwatch(value string) string {
+	c, err := parseColor(value)
+	if err != nil {
+		// Values stored before colors were validated may not parse
+		return warningStyle.Render("??") + invalidMarkPadding()
+	}
+	if c.a == 255 {
+		// Render the parsed color, as lipgloss only understands hex
+		hex := c.hex()
+		return lipgloss.NewStyle().Background(lipgloss.Color(hex)).Render("  ") + approximationMark(hex)
+	}
+
+	lightHex, darkHex := c.over(checkerLight).hex(), c.over(checkerDark).hex()
+	light, dark := lipgloss.Color(lightHex), lipgloss.Color(darkHex)
+	mark := approximationMark(lightHex)
+	if mark == " " {
+		mark = approximationMark(darkHex)
+	}
+	return lipgloss.NewStyle().Foreground(light).Background(dark).Render("▀") +
+		lipgloss.NewStyle().Foreground(dark).Background(light).Render("▀") + mark
+}
+
+// invalidMarkPadding takes the place of approximationMark next to a swatch
+// that has no color.
+func invalidMarkPadding() string {
+	if lipgloss.ColorProfile() == termenv.TrueColor {
+		return ""
+	}
+	return " "
+}
+
+// approximationMark returns "≈" when the terminal's color profile cannot show
+// the opaque color hex exactly, a space when it can, and nothing on true
+// color terminals. The 16 ANSI colors depend on the terminal's theme, so
+// they never count as exact.
+func approximationMark(hex string) string {
+	switch profile := lipgloss.ColorProfile(); profile {
+	case termenv.TrueColor:
+		return ""
+	case termenv.ANSI256:
+		if shown := termenv.ConvertToRGB(profile.Color(hex)).Hex(); strings.EqualFold(shown, hex) {
+			return " "
+		}
+	}
+	return warningStyle.Render("≈")
+}
+
+// colorProfileNote explains swatch approximations on terminals without true
+// color, and is empty otherwise.
+func colorProfileNote() string {
+	switch lipgloss.ColorProfile() {
+	case termenv.ANSI256:
+		return "This terminal shows 256 colors, ≈ marks approximate swatches."
+	case termenv.ANSI:
+		return "This terminal shows 16 colors, ≈ marks approximate swatches."
+	case termenv.Ascii:
+		return "This terminal shows no colors, rely on the codes."
+	}
+	return ""
+}
+
+func (m *model) viewColorFormat() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("Copy "+m.pickerColor.label()+" as") + "\n")
+
+	for i, f := range colorFormats {
+		label := f.label()
+		if f == m.settings.copyFormat() {
+			label += " (default)"
+		}
+		value, err := formatColor(m.pickerColor.Value, f)
+		if err != nil {
+			value = err.Error()
+		}
+		label = fmt.Sprintf("%-20s", label)
+		if m.formatCursor == i {
+			label = selectedItemStyle.Render("> " + label)
+		} else {
+			label = "  " + label
+		}
+		b.WriteString(label + " " + subtleStyle.Render(value) + "\n")
+	}
+
+	help := horizontalHelp("↑/↓ navigate", "enter copy", "s set default", "esc back", "q quit")
+	b.WriteString("\n" + help)
+
+	if m.message != "" {
+		b.WriteString("\n" + messageStyle.Render(m.message))
+	}
+
+	return b.String()
+}
+
+// viewPalettePreview fills the terminal with one block per color, laid out
+// in the grid that keeps the blocks as large as possible.
+func (m *model) viewPalettePreview() string {
+	colors := m.projects[m.selectedProject].Colors
+	width, height := m.width, m.height
+	if width == 0 || height == 0 {
+		width, height = 80, 24
+	}
+	help := horizontalHelp("esc back", "q quit")
+	height -= lipgloss.Height(help)
+	if len(colors) == 0 || height < 1 {
+		return help
+	}
+
+	// Cells are about twice as tall as they are wide
+	columns, best := 1, 0
+	for c := 1; c <= len(colors); c++ {
+		rows := (len(colors) + c - 1) / c
+		if size := min(width/c/2, height/rows); size > best {
+			columns, best = c, size
+		}
+	}
+	rows := (len(colors) + columns - 1) / columns
+
+	var lines []string
+	for r := 0; r < rows; r++ {
+		// Spread the remainders so the blocks fill the screen exactly
+		blockHeight := height / rows
+		if r < height%rows {
+			blockHeight++
+		}
+
+		var blocks []string
+		for c := 0; c < columns && r*columns+c < len(colors); c++ {
+			blockWidth := width / columns
+			if c < width%columns {
+				blockWidth++
+			}
+			blocks = append(blocks, paletteBlock(colors[r*columns+c], blockWidth, blockHeight))
+		}
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, blocks...))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, append(lines, help)...)
+}
+
+// paletteBlock renders color as a width by height block with its label and
+// code centered, in black or white, whichever contrasts more.
+func paletteBlock(color namedColor, width, height int) string {
+	bg, err := parseColor(color.Value)
+	if err != nil {
+		bg = white
+	}
+	bg = bg.over(white)
+	black := rgba{0, 0, 0, 255}
+	fg := black
+	if contrastRatio(white, bg) > contrastRatio(black, bg) {
+		fg = white
+	}
+
+	text := color.Value
+	if color.Name != "" {
+		text = lipgloss.NewStyle().Bold(true).Render(truncate(color.Name, width-2)) + "\n" + color.Value
+	}
+	return lipgloss.NewStyle().
+		Width(width).
+		Height(height).
+		MaxWidth(width).
+		MaxHeight(height).
+		Align(lipgloss.Center, lipgloss.Center).
+		Foreground(lipgloss.Color(fg.hex())).
+		Background(lipgloss.Color(bg.hex())).
+		Render(text)
+}
+
+func (m *model) viewContrast() string {
+	project := m.projects[m.selectedProject]
+	var b strings.Builder
+
+	if len(project.Colors) == 0 {
+		b.WriteString(headerStyle.Render("Contrast in "+project.Name) + "\n")
+		b.WriteString(subtleStyle.Render("No colors left to compare.") + "\n")
+		b.WriteString("\n" + horizontalHelp("esc back", "q quit"))
+		return b.String()
+	}
+
+	if m.contrastMatrix {
+		b.WriteString(headerStyle.Render("Contrast in "+project.Name) + "\n")
+		b.WriteString(contrastMatrix(project.Colors))
+		b.WriteString(subtleStyle.Render("Rows are text, columns are backgrounds. ✓ passes AA, ~ only for large text.") + "\n")
+		b.WriteString("\n" + horizontalHelp("m pair", "esc back", "q quit"))
+		return b.String()
+	}
+
+	text := project.Colors[m.contrastText]
+	textColor, _ := parseColor(text.Value)
+	b.WriteString(headerStyle.Render("Contrast of "+text.label()) + "\n")
+
+	for i, color := range project.Colors {
+		bg, _ := parseColor(color.Value)
+		line := fmt.Sprintf("%s %s %s", colorSwatch(color.Value), inlineCodeStyle.Render(color.Value), formatRatio(contrastRatio(textColor, bg)))
+		if color.Name != "" {
+			line += " " + subtleStyle.Render(color.Name)
+		}
+		if m.contrastBg == i {
+			b.WriteString(selectedItemStyle.Render("> ") + line + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	bg, _ := parseColor(project.Colors[m.contrastBg].Value)
+	ratio := contrastRatio(textColor, bg)
+	sample := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(textColor.over(bg.over(white)).hex())).
+		Background(lipgloss.Color(bg.over(white).hex())).
+		PaddingTop(1).
+		PaddingBottom(1)
+	b.WriteString("\n" + lipgloss.JoinHorizontal(lipgloss.Top,
+		sample.Bold(true).PaddingLeft(2).Render("Aa "),
+		sample.PaddingRight(2).Render("The quick brown fox"),
+	) + "\n\n")
+
+	badges := []string{"Ratio " + formatRatio(ratio)}
+	for _, check := range wcagChecks {
+		if ratio >= check.minRatio {
+			badges = append(badges, passBadgeStyle.Render(check.label+" ✓"))
+		} else {
+			badges = append(badges, failBadgeStyle.Render(check.label+" ✗"))
+		}
+	}
+	b.WriteString(strings.Join(badges, " ") + "\n")
+
+	help := horizontalHelp("↑/↓ background", "x swap", "m matrix", "esc back", "q quit")
+	b.WriteString("\n" + help)
+
+	return b.String()
+}
+
+// contrastMatrix renders the contrast ratio of every pair of colors, each
+// cell written in its text color on its background.
+func contrastMatrix(colors []namedColor) string {
+	var b strings.Builder
+
+	b.WriteString("   ")
+	for _, color := range colors {
+		b.WriteString("  " + colorSwatch(color.Value) + "   ")
+	}
+	b.WriteString("\n")
+
+	for _, text := range colors {
+		fg, _ := parseColor(text.Value)
+		b.WriteString(colorSwatch(text.Value) + " ")
+		for _, color := range colors {
+			bg, _ := parseColor(color.Value)
+			ratio := contrastRatio(fg, bg)
+			mark := "✗"
+			switch {
+			case ratio >= 4.5:
+				mark = "✓"
+			case ratio >= 3:
+				mark = "~"
+			}
+			cell := lipgloss.NewStyle().
+				Foreground(lipgloss.Color(fg.over(bg.over(white)).hex())).
+				Background(lipgloss.Color(bg.over(white).hex())).
+				Render(fmt.Sprintf("%5.1f%s", math.Floor(ratio*10)/10, mark))
+			b.WriteString(cell + " ")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (m *model) viewGeneratePalette() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("Generate from "+m.paletteBase.label()) + "\n")
+	b.WriteString(colorSwatch(m.paletteBase.Value) + " " + inlineCodeStyle.Render(m.paletteBase.Value) + "\n")
+
+	kind := ""
+	for i, entry := range m.palette {
+		if entry.kind != kind {
+			kind = entry.kind
+			b.WriteString("\n" + subtleStyle.Render(kind) + "\n")
+		}
+
+		check := "[ ]"
+		if m.paletteSelected[i] {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("%s %s %s %s", check, colorSwatch(entry.color.Value), inlineCodeStyle.Render(entry.color.Value), entry.color.Name)
+		if m.paletteCursor == i {
+			b.WriteString(selectedItemStyle.Render("> ") + line + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	help := horizontalHelp("↑/↓ navigate", "space select", "a all", "enter add", "esc back", "q quit")
 	b.WriteString("\n" + help)
 
 	if m.message != "" {
@@ -176,6 +602,74 @@ func (m *model) viewColorList() string {
 	return b.String()
 }
 
+func (m *model) viewExport() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("Export "+m.projects[m.selectedProject].Name) + "\n")
+
+	for i, f := range exportFormats {
+		label := fmt.Sprintf("%-24s", f.label)
+		if m.exportCursor == i {
+			b.WriteString(selectedItemStyle.Render("> "+label) + " " + subtleStyle.Render(f.name) + "\n")
+		} else {
+			b.WriteString("  " + label + " " + subtleStyle.Render(f.name) + "\n")
+		}
+	}
+
+	b.WriteString("\n" + subtleStyle.Render("Files are written to the current directory.") + "\n")
+	help := horizontalHelp("↑/↓ navigate", "enter export", "esc back", "q quit")
+	b.WriteString("\n" + help)
+
+	return b.String()
+}
+
+func (m *model) viewImport() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("Import Colors") + "\n")
+	prompt := fmt.Sprintf("Palette file: %s", m.inputBuffer)
+	b.WriteString(inputStyle.Render(prompt) + "\n")
+	if m.inputError != "" {
+		b.WriteString(messageStyle.Render(m.inputError) + "\n")
+	}
+	b.WriteString(subtleStyle.Render(".gpl, .ase, .css/.scss, design tokens .json, a Tailwind config or a PNG/JPEG image") + "\n\n")
+	b.WriteString(horizontalHelp("enter preview", "esc cancel"))
+	return b.String()
+}
+
+func (m *model) viewImportPreview() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("Import from "+filepath.Base(m.importPath)) + "\n")
+
+	if len(m.imported) == 0 {
+		b.WriteString(subtleStyle.Render("No new colors found.") + "\n")
+	}
+	for i, color := range m.imported {
+		check := "[ ]"
+		if m.importSelected[i] {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("%s %s %s", check, colorSwatch(color.Value), inlineCodeStyle.Render(color.Value))
+		if color.Name != "" {
+			line += " " + color.Name
+		}
+		if color.Group != "" {
+			line += " " + subtleStyle.Render("· "+color.Group)
+		}
+		if m.cursor == i {
+			b.WriteString(selectedItemStyle.Render("> ") + line + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	if m.importSkipped > 0 {
+		b.WriteString("\n" + subtleStyle.Render(fmt.Sprintf("Skipping %s already in %s.", pluralize(m.importSkipped, "color", "colors"), m.projects[m.selectedProject].Name)) + "\n")
+	}
+
+	help := horizontalHelp("↑/↓ navigate", "space select", "a all", "enter/y import", "esc/n cancel", "q quit")
+	b.WriteString("\n" + help)
+
+	return b.String()
+}
+
 func (m *model) viewUrlList() string {
 	project := m.projects[m.selectedProject]
 	var b strings.Builder
@@ -186,15 +680,37 @@ func (m *model) viewUrlList() string {
 		b.WriteString(subtleStyle.Render("No URLs yet. Press 'n' to add one.") + "\n")
 	} else {
 		for i, namedUrl := range project.Urls {
+			line := namedUrl.Name
+			if namedUrl.Environment != "" {
+				line += " " + environmentStyle(namedUrl.Environment).Render(namedUrl.Environment)
+			}
+			line += " " + subtleStyle.Render(namedUrl.URL)
+			for _, tag := range namedUrl.Tags {
+				line += " " + subtleStyle.Render("#"+tag)
+			}
+
 			if m.cursor == i {
-				b.WriteString(selectedItemStyle.Render("> " + namedUrl.Name) + "\n")
+				b.WriteString(selectedItemStyle.Render("> ") + selectedItemStyle.Render(line) + "\n")
+				var details []string
+				if namedUrl.Description != "" {
+					details = append(details, namedUrl.Description)
+				}
+				if !namedUrl.Created.IsZero() {
+					details = append(details, "added "+timeAgo(namedUrl.Created))
+				}
+				if used := m.urlUsage[project.Name][namedUrl.Name]; !used.IsZero() {
+					details = append(details, "last used "+timeAgo(used))
+				}
+				if len(details) > 0 {
+					b.WriteString("    " + subtleStyle.Render(strings.Join(details, " · ")) + "\n")
+				}
 			} else {
-				b.WriteString("  " + namedUrl.Name + "\n")
+				b.WriteString("  " + line + "\n")
 			}
 		}
 	}
 
-	help := horizontalHelp("↑/↓ navigate", "enter copy", "n new", "d delete", "esc back", "q quit")
+	help := horizontalHelp("↑/↓ navigate", "enter copy", "o open", "O open all", "n new", "d delete", "esc back", "q quit")
 	b.WriteString("\n" + help)
 
 	if m.message != "" {
@@ -216,10 +732,40 @@ func (m *model) viewAddProject() string {
 func (m *model) viewAddColor() string {
 	var b strings.Builder
 	b.WriteString(headerStyle.Render("Add New Color") + "\n")
-	prompt := fmt.Sprintf("HEX color: %s", m.inputBuffer)
-	b.WriteString(inputStyle.Render(prompt) + "\n\n")
-	b.WriteString(helpStyle.Render("Enter HEX (e.g., #FF5F87)") + "\n")
-	b.WriteString(horizontalHelp("enter save", "esc cancel"))
+
+	labels := [colorFieldCount]string{"Color", "Name", "Group", "Notes", "Tags"}
+	for i, label := range labels {
+		prompt := fmt.Sprintf("%s: %s", label, m.colorFields[i])
+		if m.focusedField == i {
+			b.WriteString(inputStyle.Render(prompt) + "\n")
+		} else {
+			b.WriteString(subtleStyle.Render(prompt) + "\n")
+		}
+	}
+
+	if m.inputError != "" {
+		b.WriteString(messageStyle.Render(m.inputError) + "\n")
+	}
+
+	similar := m.similarColors()
+	if len(similar) > 0 {
+		b.WriteString("\n" + warningStyle.Render("⚠ Near-identical colors already exist:") + "\n")
+		for _, match := range similar {
+			b.WriteString(fmt.Sprintf("  %s %s %s %s\n",
+				colorSwatch(match.color.Value),
+				inlineCodeStyle.Render(match.color.Value),
+				match.color.label(),
+				subtleStyle.Render(fmt.Sprintf("in %s · ΔE %.2f", match.project, match.deltaE))))
+		}
+	}
+
+	b.WriteString("\n" + helpStyle.Render("Enter HEX, rgb(), hsl(), oklch() or a CSS name (e.g., #FF5F87)") + "\n")
+	b.WriteString(helpStyle.Render("Name, group, notes and comma-separated tags are optional") + "\n")
+	if len(similar) > 0 {
+		b.WriteString(horizontalHelp("enter save", "ctrl+r reuse closest", "tab switch fields", "esc cancel"))
+	} else {
+		b.WriteString(horizontalHelp("enter save", "tab switch fields", "esc cancel"))
+	}
 	return b.String()
 }
 
@@ -227,17 +773,17 @@ func (m *model) viewAddUrl() string {
 	var b strings.Builder
 	b.WriteString(headerStyle.Render("Add New URL") + "\n")
 
-	namePrompt := fmt.Sprintf("Name: %s", m.urlNameBuffer)
-	urlPrompt := fmt.Sprintf("URL: %s", m.inputBuffer)
-
-	if m.focusedField == 0 {
-		b.WriteString(inputStyle.Render(namePrompt) + "\n")
-		b.WriteString(subtleStyle.Render(urlPrompt) + "\n\n")
-	} else {
-		b.WriteString(subtleStyle.Render(namePrompt) + "\n")
-		b.WriteString(inputStyle.Render(urlPrompt) + "\n\n")
+	labels := [urlFieldCount]string{"Name", "URL", "Description", "Environment", "Tags"}
+	for i, label := range labels {
+		prompt := fmt.Sprintf("%s: %s", label, m.urlFields[i])
+		if m.focusedField == i {
+			b.WriteString(inputStyle.Render(prompt) + "\n")
+		} else {
+			b.WriteString(subtleStyle.Render(prompt) + "\n")
+		}
 	}
 
+	b.WriteString("\n" + helpStyle.Render("Description, environment (e.g., prod, staging, dev) and comma-separated tags are optional") + "\n")
 	b.WriteString(horizontalHelp("enter next/save", "tab switch fields", "esc cancel"))
 	return b.String()
 }
@@ -254,6 +800,116 @@ func (m *model) viewConfirmDeleteProject() string {
 	return b.String()
 }
 
+func (m *model) viewRecovery() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("⚠️ Data file recovery") + "\n")
+	b.WriteString(m.recoveryNote + "\n\n")
+
+	if len(m.backups) == 0 {
+		b.WriteString(subtleStyle.Render("No readable backups were found.") + "\n\n")
+	} else {
+		b.WriteString("Choose a backup to restore, or start with an empty library:\n\n")
+	}
+
+	var options []string
+	for _, backup := range m.backups {
+		options = append(options, fmt.Sprintf("Restore %s (saved %s, %s)",
+			filepath.Base(backup.path), backup.modTime.Format("2006-01-02 15:04"), pluralize(len(backup.projects), "project", "projects")))
+	}
+	options = append(options, "Start fresh")
+
+	for i, option := range options {
+		if m.cursor == i {
+			b.WriteString(selectedItemStyle.Render("> "+option) + "\n")
+		} else {
+			b.WriteString("  " + option + "\n")
+		}
+	}
+
+	help := horizontalHelp("↑/↓ navigate", "enter select", "q quit")
+	b.WriteString("\n" + help)
+
+	if m.message != "" {
+		b.WriteString("\n" + messageStyle.Render(m.message))
+	}
+
+	return b.String()
+}
+
+func (m *model) viewLibraryList() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("📚 Libraries") + "\n")
+
+	for i, lib := range m.libraries {
+		name := lib.Name
+		if lib.Name == m.libraryName {
+			name += " (open)"
+		}
+		if m.cursor == i {
+			b.WriteString(selectedItemStyle.Render("> " + name))
+		} else {
+			b.WriteString("  " + name)
+		}
+		b.WriteString(" " + subtleStyle.Render(lib.Path) + "\n")
+	}
+
+	b.WriteString("\n" + subtleStyle.Render("Add libraries with 'diamonds library add <name> <path>'") + "\n")
+	help := horizontalHelp("↑/↓ navigate", "enter open", "esc back", "q quit")
+	b.WriteString("\n" + help)
+
+	if m.message != "" {
+		b.WriteString("\n" + messageStyle.Render(m.message))
+	}
+
+	return b.String()
+}
+
+// libraryTitle returns the project list title for the named library.
+func libraryTitle(name string) string {
+	if name == defaultLibraryName {
+		return "🪩 DIAMONDS "
+	}
+	return "🪩 DIAMONDS · " + name + " "
+}
+
 func horizontalHelp(keys ...string) string {
 	return helpStyle.Render(strings.Join(keys, " • "))
 }
+
+// environmentStyle returns the badge style of a URL environment, colored
+// for the well-known ones.
+func environmentStyle(env string) lipgloss.Style {
+	bg, ok := environmentColors[strings.ToLower(env)]
+	if !ok {
+		bg = lipgloss.Color("#5F5F87")
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#F1F1F1")).Background(bg).Padding(0, 1)
+}
+
+// timeAgo describes how long ago t was, e.g. "3 days ago".
+func timeAgo(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return pluralize(int(d/time.Minute), "minute", "minutes") + " ago"
+	case d < 24*time.Hour:
+		return pluralize(int(d/time.Hour), "hour", "hours") + " ago"
+	case d < 30*24*time.Hour:
+		return pluralize(int(d/(24*time.Hour)), "day", "days") + " ago"
+	}
+	return "on " + t.Format("2 Jan 2006")
+}
+
+// truncate shortens s to at most n runes, ending it with an ellipsis.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n < 1 {
+		return ""
+	}
+	return string(runes[:n-1]) + "…"
+}
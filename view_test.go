@@ -0,0 +1,27 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestColorSwatchRendersEveryNotation(t *testing.T) {
+	previous := lipgloss.ColorProfile()
+	t.Cleanup(func() { lipgloss.SetColorProfile(previous) })
+
+	for _, profile := range []termenv.Profile{termenv.TrueColor, termenv.ANSI256} {
+		lipgloss.SetColorProfile(profile)
+		want := colorSwatch("#FF69B4")
+		if !strings.Contains(want, "\x1b[") {
+			t.Fatalf("swatch %q has no color", want)
+		}
+		for _, value := range []string{"#ff69b4", "rgb(255, 105, 180)", "hotpink"} {
+			if got := colorSwatch(value); got != want {
+				t.Errorf("profile %v: swatch of %s is %q, want %q", profile, value, got, want)
+			}
+		}
+	}
+}